@@ -0,0 +1,167 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// sessions not reported on bng.stats for this long are considered gone
+	bngSessionTimeout       = 5 * time.Minute
+	bngSessionSweepInterval = 30 * time.Second
+)
+
+// bngSession is a PPPoE/IPoE session reported on bng.stats
+type bngSession struct {
+	Mac            string
+	SessionID      string
+	STag           string
+	CTag           string
+	Ip             string
+	OnuSerial      string
+	AttachmentType string
+	DeviceID       string
+	PortNumber     string
+	Start          time.Time
+
+	lastSeen time.Time
+	// last value reported for each statistic, used to feed the counters
+	lastValues map[string]float64
+}
+
+// bngSessionCache keeps the active BNG sessions by MAC, session id and tags
+type bngSessionCache struct {
+	sync.Mutex
+	sessions map[string]*bngSession
+}
+
+var bngSessions = &bngSessionCache{
+	sessions: make(map[string]*bngSession),
+}
+
+func (s *bngSession) key() string {
+	return s.Mac + "/" + s.SessionID + "/" + s.STag + "/" + s.CTag
+}
+
+// labels identifying the session on the counters and the start time
+func (s *bngSession) labels() []string {
+	return []string{s.Mac, s.SessionID, s.STag, s.CTag}
+}
+
+// labels of bng_session_info
+func (s *bngSession) infoLabels() []string {
+	return append(s.labels(), s.Ip, s.OnuSerial, s.AttachmentType, s.DeviceID, s.PortNumber)
+}
+
+func (s *bngSession) sameInfo(other *bngSession) bool {
+	return s.Ip == other.Ip &&
+		s.OnuSerial == other.OnuSerial &&
+		s.AttachmentType == other.AttachmentType &&
+		s.DeviceID == other.DeviceID &&
+		s.PortNumber == other.PortNumber
+}
+
+// update refreshes the session and returns a copy of the stored one
+// together with a copy of the previous one. The previous session is nil for
+// new sessions, which get the given start time. The copies are what the
+// labels are built from, as the stored session changes under the lock.
+func (c *bngSessionCache) update(session *bngSession, start time.Time) (bngSession, *bngSession) {
+	c.Lock()
+	defer c.Unlock()
+
+	stored, ok := c.sessions[session.key()]
+	if !ok {
+		session.Start = start
+		session.lastSeen = time.Now()
+		session.lastValues = make(map[string]float64)
+		c.sessions[session.key()] = session
+		return *session, nil
+	}
+
+	old := *stored
+	stored.Ip = session.Ip
+	stored.OnuSerial = session.OnuSerial
+	stored.AttachmentType = session.AttachmentType
+	stored.DeviceID = session.DeviceID
+	stored.PortNumber = session.PortNumber
+	stored.lastSeen = time.Now()
+	return *stored, &old
+}
+
+// delta returns the increase of a statistic of the session with the given
+// key since it was last reported. A value lower than the previous one means
+// the statistic was reset.
+func (c *bngSessionCache) delta(key string, name string, value float64) float64 {
+	c.Lock()
+	defer c.Unlock()
+
+	session, ok := c.sessions[key]
+	if !ok {
+		return 0
+	}
+	last, ok := session.lastValues[name]
+	session.lastValues[name] = value
+	if !ok || value < last {
+		return value
+	}
+	return value - last
+}
+
+// get returns a copy of the session with the given key
+func (c *bngSessionCache) get(key string) (bngSession, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	session, ok := c.sessions[key]
+	if !ok {
+		return bngSession{}, false
+	}
+	return *session, true
+}
+
+// expire removes the sessions not seen since the given time and returns
+// copies of them
+func (c *bngSessionCache) expire(before time.Time) []bngSession {
+	c.Lock()
+	defer c.Unlock()
+
+	var expired []bngSession
+	for key, session := range c.sessions {
+		if session.lastSeen.Before(before) {
+			expired = append(expired, *session)
+			delete(c.sessions, key)
+		}
+	}
+	return expired
+}
+
+// parseBngTimestamp accepts RFC3339 dates as well as epoch milliseconds
+func parseBngTimestamp(timestamp string) (time.Time, bool) {
+	timestamp = strings.TrimSpace(timestamp)
+	if timestamp == "" {
+		return time.Time{}, false
+	}
+	if ts, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+		return ts, true
+	}
+	if ms, err := strconv.ParseInt(timestamp, 10, 64); err == nil {
+		return time.Unix(0, ms*int64(time.Millisecond)), true
+	}
+	return time.Time{}, false
+}
@@ -4,14 +4,19 @@ go 1.16
 
 require (
 	github.com/Shopify/sarama v1.32.0
+	github.com/cespare/xxhash/v2 v2.1.2 // indirect
 	github.com/gfremex/logrus-kafka-hook v0.0.0-20180109031623-f62e125fcbfe
 	github.com/golang/protobuf v1.5.2
+	github.com/golang/snappy v0.0.4
 	github.com/opencord/device-management-interface v1.4.0
 	github.com/opencord/voltha-protos/v5 v5.2.4
-	github.com/prometheus/client_golang v1.0.0
-	github.com/prometheus/common v0.6.0 // indirect
-	github.com/prometheus/procfs v0.0.3 // indirect
+	github.com/prometheus/client_golang v1.10.0
+	github.com/prometheus/client_model v0.2.0
+	github.com/prometheus/common v0.18.0
+	github.com/prometheus/procfs v0.7.3 // indirect
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.7.0
-	gopkg.in/yaml.v2 v2.2.3
+	google.golang.org/grpc v1.44.0
+	google.golang.org/protobuf v1.27.1
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,265 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	cDefaultInfluxBatchSize     = 5000
+	cDefaultInfluxFlushInterval = 10 * time.Second
+	cDefaultInfluxTimeout       = 10 * time.Second
+	// batches buffered while InfluxDB is slow or down, beyond which the
+	// lines are dropped
+	cInfluxMaxBatches = 10
+)
+
+var influxDroppedLines = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "kte_influxdb_dropped_lines_total",
+		Help: "Lines dropped because the InfluxDB buffer was full",
+	})
+
+// influxWriter writes the samples of the topic handlers as InfluxDB line
+// protocol, over HTTP or to a local file. A KPI group is a measurement, the
+// metrics of the group reported together being the fields of a point.
+type influxWriter struct {
+	sync.Mutex
+	conf   InfluxInfo
+	client *http.Client
+	file   *os.File
+	points []*influxPoint
+	// the buffered points by series and timestamp, for the samples of the
+	// same report to be fields of the same point
+	index map[influxPointKey]*influxPoint
+	// signals run that a batch is buffered
+	full chan struct{}
+}
+
+// influxPoint is a line, the series being the escaped measurement and tags
+type influxPoint struct {
+	series string
+	fields map[string]float64
+	ts     time.Time
+}
+
+type influxPointKey struct {
+	series string
+	ts     int64
+}
+
+func newInfluxWriter(conf InfluxInfo) (*influxWriter, error) {
+	if conf.BatchSize == 0 {
+		conf.BatchSize = cDefaultInfluxBatchSize
+	}
+	if conf.FlushInterval == 0 {
+		conf.FlushInterval = cDefaultInfluxFlushInterval
+	}
+	if conf.Timeout == 0 {
+		conf.Timeout = cDefaultInfluxTimeout
+	}
+
+	w := &influxWriter{conf: conf, index: make(map[influxPointKey]*influxPoint), full: make(chan struct{}, 1)}
+	switch {
+	case conf.File != "":
+		file, err := os.OpenFile(conf.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		w.file = file
+	case conf.Url != "":
+		w.client = &http.Client{Timeout: conf.Timeout}
+	default:
+		return nil, fmt.Errorf("either the url or the file of the InfluxDB output is needed")
+	}
+	return w, nil
+}
+
+// run flushes the buffered lines every flush interval and whenever a batch
+// is buffered
+func (w *influxWriter) run() {
+	ticker := time.NewTicker(w.conf.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.full:
+		}
+		w.flush()
+	}
+}
+
+// Write implements Sink, buffering the sample as a field of the point of
+// its KPI group, the families outside the groups being a measurement of
+// their own with a single value field. The topic handlers never wait for
+// InfluxDB, run is signalled to flush once a batch is buffered and the
+// points are dropped when too many batches are pending.
+func (w *influxWriter) Write(s *Sample) {
+	// line protocol has no NaN nor infinity
+	if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+		return
+	}
+	measurement, field := s.Group, s.Name
+	if measurement == "" {
+		measurement, field = s.Name, "value"
+	}
+	key := influxPointKey{series: influxSeries(measurement, s.Labels()), ts: s.Timestamp.UnixNano()}
+
+	w.Lock()
+	if point, ok := w.index[key]; ok {
+		point.fields[field] = s.Value
+		w.Unlock()
+		return
+	}
+	if len(w.points) >= w.conf.BatchSize*cInfluxMaxBatches {
+		w.Unlock()
+		influxDroppedLines.Inc()
+		return
+	}
+	point := &influxPoint{series: key.series, fields: map[string]float64{field: s.Value}, ts: s.Timestamp}
+	w.points = append(w.points, point)
+	w.index[key] = point
+	full := len(w.points) >= w.conf.BatchSize
+	w.Unlock()
+
+	if full {
+		select {
+		case w.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Delete implements Sink, InfluxDB keeps the points already written
+func (w *influxWriter) Delete(name string, labelValues []string) {}
+
+func (w *influxWriter) flush() {
+	w.Lock()
+	points := w.points
+	w.points = nil
+	w.index = make(map[influxPointKey]*influxPoint)
+	w.Unlock()
+
+	if len(points) == 0 {
+		return
+	}
+	var data []byte
+	for _, point := range points {
+		data = appendInfluxLine(data, point.series, point.fields, point.ts)
+	}
+	if err := w.send(data); err != nil {
+		logger.Error("Cannot write [%d] points to InfluxDB: %s", len(points), err.Error())
+	}
+}
+
+func (w *influxWriter) send(data []byte) error {
+	if w.file != nil {
+		_, err := w.file.Write(data)
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.conf.Url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("User-Agent", "kafka-topic-exporter")
+	if w.conf.Token != "" {
+		req.Header.Set("Authorization", "Token "+w.conf.Token)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("server returned %s: %s", resp.Status, bytes.TrimSpace(message))
+	}
+	return nil
+}
+
+// the line protocol escaping, a newline would end the line and a backslash
+// would escape the next character
+var (
+	influxMeasurementEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, ",", `\,`, " ", `\ `)
+	influxKeyEscaper         = strings.NewReplacer(`\`, `\\`, "\n", `\n`, ",", `\,`, "=", `\=`, " ", `\ `)
+)
+
+// influxSeries encodes the measurement and the tags of a line
+//
+//	measurement,tag=value,...
+//
+// with the tags sorted and the empty ones left out, as InfluxDB rejects them
+func influxSeries(measurement string, tags map[string]string) string {
+	b := []byte(influxMeasurementEscaper.Replace(measurement))
+
+	keys := make([]string, 0, len(tags))
+	for key, value := range tags {
+		if key != "" && value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		b = append(b, ',')
+		b = append(b, influxKeyEscaper.Replace(key)...)
+		b = append(b, '=')
+		b = append(b, influxKeyEscaper.Replace(tags[key])...)
+	}
+	return string(b)
+}
+
+// appendInfluxLine encodes a point, the fields sorted
+//
+//	series field=value,... timestamp
+func appendInfluxLine(b []byte, series string, fields map[string]float64, ts time.Time) []byte {
+	b = append(b, series...)
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		if i == 0 {
+			b = append(b, ' ')
+		} else {
+			b = append(b, ',')
+		}
+		b = append(b, influxKeyEscaper.Replace(key)...)
+		b = append(b, '=')
+		b = strconv.AppendFloat(b, fields[key], 'g', -1, 64)
+	}
+
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, ts.UnixNano(), 10)
+	return append(b, '\n')
+}
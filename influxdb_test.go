@@ -0,0 +1,170 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/opencord/voltha-protos/v5/go/voltha"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendInfluxLine(t *testing.T) {
+	ts := time.Unix(1636106400, 5)
+	series := influxSeries("onos_port", map[string]string{
+		"port_id":   "16",
+		"device_id": "of:0000000000000001",
+		"port_name": "",
+	})
+	assert.Equal(t, "onos_port,device_id=of:0000000000000001,port_id=16", series)
+	line := appendInfluxLine(nil, series, map[string]float64{"onos_tx_bytes_total": 1024.5, "onos_rx_bytes_total": 3}, ts)
+	assert.Equal(t, "onos_port,device_id=of:0000000000000001,port_id=16 onos_rx_bytes_total=3,onos_tx_bytes_total=1024.5 1636106400000000005\n", string(line))
+
+	line = appendInfluxLine(line[:0], influxSeries("bng", nil), map[string]float64{"value": 3}, ts)
+	assert.Equal(t, "bng value=3 1636106400000000005\n", string(line))
+}
+
+func TestAppendInfluxLineEscaping(t *testing.T) {
+	series := influxSeries("a b,c=d\\", map[string]string{
+		"port name": "eth 0,1=2",
+		"path":      `C:\temp\`,
+		"descr":     "line1\nline2",
+	})
+	line := appendInfluxLine(nil, series, map[string]float64{"rx bytes,total=": 1}, time.Unix(0, 1))
+	assert.Equal(t, `a\ b\,c=d\\,descr=line1\nline2,path=C:\\temp\\,port\ name=eth\ 0\,1\=2 rx\ bytes\,total\==1 1`+"\n", string(line))
+}
+
+func TestInfluxWriterGroups(t *testing.T) {
+	logger.Setup("", "ERROR")
+	w, err := newInfluxWriter(InfluxInfo{File: os.DevNull})
+	assert.NoError(t, err)
+	defer w.file.Close()
+	defer func(sinks []Sink, cache *sourceTimestampCache) {
+		metricSinks, sourceTimestamps = sinks, cache
+	}(metricSinks, sourceTimestamps)
+	metricSinks = []Sink{prometheusSink{}, pipelineSink{w}}
+	sourceTimestamps = newSourceTimestampCache()
+	newTestRegistry()
+
+	// the metrics of a report are the fields of a point of the group,
+	// stamped with the time of the message
+	exportOnosKPI(OnosKPI{
+		DeviceID:  "of:0000000000000001",
+		Ports:     []*OnosPort{{PortID: "16", TxBytes: 1024, RxBytes: 2048}},
+		Timestamp: onosTimestamp(time.Unix(1636106400, 0)),
+	})
+	exportVolthaKPIevent2(&voltha.KpiEvent2{
+		Type: voltha.KpiEventType_slice,
+		SliceData: []*voltha.MetricInformation{{
+			Metadata: &voltha.MetricMetaData{
+				Title:    "PON_Optical",
+				DeviceId: "onu-1",
+				SerialNo: "BBSM00000001",
+				Ts:       1636106410,
+			},
+			Metrics: map[string]float32{"transmit_power": 2.5, "receive_power": -18},
+		}},
+	})
+
+	upTxBytes := 100.0
+	exportOnosBngKPI(OnosBngKPI{
+		Mac:            "2e:60:00:00:00:01",
+		PppoeSessionId: 7,
+		AttachmentType: "PPPoE",
+		STag:           900,
+		CTag:           901,
+		UpTxBytes:      &upTxBytes,
+		Timestamp:      "2021-11-05T10:00:20Z",
+	})
+
+	var lines []string
+	for _, point := range w.points {
+		lines = append(lines, string(appendInfluxLine(nil, point.series, point.fields, point.ts)))
+	}
+	assert.Contains(t, lines, "onos_port,device_id=of:0000000000000001,port_id=16 "+
+		"onos_rx_bytes_total=2048,onos_rx_drop_packets_total=0,onos_rx_packets_total=0,"+
+		"onos_tx_bytes_total=1024,onos_tx_drop_packets_total=0,onos_tx_packets_total=0 1636106400000000000\n")
+	assert.Contains(t, lines, "bng,c_tag=901,mac_address=2e:60:00:00:00:01,s_tag=900,session_id=7 bng_up_tx_bytes_total=100 1636106420000000000\n")
+	// the families outside the groups are a measurement of their own
+	assert.Contains(t, lines, "bng_session_info,c_tag=901,mac_address=2e:60:00:00:00:01,s_tag=900,session_id=7,type=PPPoE value=1 1636106420000000000\n")
+	assert.Contains(t, lines, "voltha_pon_optical,device_id=onu-1,pon_id=NA,port_number=NA,serial_number=BBSM00000001,title=PON_Optical "+
+		"voltha_onu_received_optical_power=-18,voltha_onu_transmit_optical_power=2.5 1636106410000000000\n")
+}
+
+func TestInfluxWriterBatches(t *testing.T) {
+	logger.Setup("", "ERROR")
+	dir, err := ioutil.TempDir("", "kte-influxdb")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "points.lp")
+
+	w, err := newInfluxWriter(InfluxInfo{File: file, BatchSize: 2})
+	assert.NoError(t, err)
+	defer w.file.Close()
+
+	// one point per sample, each at its own time
+	var second int64
+	sample := func(value float64) *Sample {
+		second++
+		return &Sample{
+			Name:        "onos_rx_bytes_total",
+			Kind:        GaugeSample,
+			LabelNames:  []string{"device_id", "port_id"},
+			LabelValues: []string{"of:0000000000000001", "16"},
+			Value:       value,
+			Timestamp:   time.Unix(1636106400+second, 0),
+			Group:       "onos_port",
+		}
+	}
+
+	// the writer only signals run once a batch is buffered
+	w.Write(sample(1))
+	assert.Len(t, w.full, 0)
+	w.Write(sample(math.NaN()))
+	w.Write(sample(2))
+	assert.Len(t, w.full, 1)
+	w.Write(sample(3))
+	w.Write(sample(4))
+	assert.Len(t, w.full, 1)
+
+	// the points beyond the pending batches are dropped, a sample of a
+	// buffered point is still written to it
+	dropped := counterValue(t, influxDroppedLines)
+	for i := 0; i < 2*cInfluxMaxBatches; i++ {
+		w.Write(sample(5))
+	}
+	assert.Equal(t, dropped+4, counterValue(t, influxDroppedLines))
+	last := sample(6)
+	last.Timestamp = time.Unix(1636106401, 0)
+	last.Name = "onos_tx_bytes_total"
+	w.Write(last)
+	assert.Equal(t, dropped+4, counterValue(t, influxDroppedLines))
+
+	w.flush()
+	data, err := ioutil.ReadFile(file)
+	assert.NoError(t, err)
+	lines := string(data)
+	assert.Contains(t, lines, "onos_port,device_id=of:0000000000000001,port_id=16 onos_rx_bytes_total=1,onos_tx_bytes_total=6 1636106401000000000\n")
+	assert.Contains(t, lines, "onos_port,device_id=of:0000000000000001,port_id=16 onos_rx_bytes_total=4 1636106405000000000\n")
+	assert.NotContains(t, lines, "NaN")
+	assert.Equal(t, 2*cInfluxMaxBatches, strings.Count(lines, "\n"))
+}
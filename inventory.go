@@ -0,0 +1,364 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"gerrit.opencord.org/kafka-topic-exporter/utils"
+	"github.com/opencord/voltha-protos/v5/go/voltha"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	inventoryTypeOlt = "olt"
+	inventoryTypeOnu = "onu"
+)
+
+// InventoryDevice is what is known of an OLT or ONU, the serial number
+// being the common identity of the VOLTHA, ONOS, BNG and DMI views
+type InventoryDevice struct {
+	Type            string            `yaml:"type"`
+	SerialNumber    string            `yaml:"serial_number"`
+	DeviceID        string            `yaml:"device_id"`
+	LogicalDeviceID string            `yaml:"logical_device_id"`
+	OnosDeviceID    string            `yaml:"onos_device_id"`
+	DmiDeviceUUID   string            `yaml:"dmi_device_uuid"`
+	OltSerial       string            `yaml:"olt_serial"`
+	PonID           string            `yaml:"pon_id"`
+	SubscriberID    string            `yaml:"subscriber_id"`
+	Labels          map[string]string `yaml:"labels"`
+}
+
+// labels of kte_device_info, the configured extra labels are added after
+var inventoryLabels = []string{
+	"type",
+	"serial_number",
+	"device_id",
+	"logical_device_id",
+	"onos_device_id",
+	"dmi_device_uuid",
+	"olt_serial",
+	"pon_id",
+	"subscriber_id",
+}
+
+func (d *InventoryDevice) labelValues(extraLabels []string) []string {
+	values := []string{
+		d.Type,
+		d.SerialNumber,
+		d.DeviceID,
+		d.LogicalDeviceID,
+		d.OnosDeviceID,
+		d.DmiDeviceUUID,
+		d.OltSerial,
+		d.PonID,
+		d.SubscriberID,
+	}
+	for _, label := range extraLabels {
+		values = append(values, d.Labels[label])
+	}
+	return values
+}
+
+// merge fills the fields of d that are empty from the other device
+func (d *InventoryDevice) merge(other *InventoryDevice) {
+	fields := []struct {
+		dst *string
+		src string
+	}{
+		{&d.Type, other.Type},
+		{&d.DeviceID, other.DeviceID},
+		{&d.LogicalDeviceID, other.LogicalDeviceID},
+		{&d.OnosDeviceID, other.OnosDeviceID},
+		{&d.DmiDeviceUUID, other.DmiDeviceUUID},
+		{&d.OltSerial, other.OltSerial},
+		{&d.PonID, other.PonID},
+		{&d.SubscriberID, other.SubscriberID},
+	}
+	for _, field := range fields {
+		if *field.dst == "" {
+			*field.dst = field.src
+		}
+	}
+	for key, value := range other.Labels {
+		if _, ok := d.Labels[key]; !ok {
+			d.Labels[key] = value
+		}
+	}
+}
+
+// deviceInventory joins the identities of the devices, from the static
+// inventory file and learned from the messages, into kte_device_info
+type deviceInventory struct {
+	sync.Mutex
+	// by serial number, the static entries win over the learned ones
+	learned     map[string]*InventoryDevice
+	static      map[string]*InventoryDevice
+	dmiDevices  map[string]bool
+	extraLabels []string
+	info        *prometheus.Desc
+}
+
+// inventory is nil unless the inventory is enabled in the configuration
+var inventory *deviceInventory
+
+func newDeviceInventory(conf InventoryInfo) *deviceInventory {
+	inv := &deviceInventory{
+		learned:    make(map[string]*InventoryDevice),
+		static:     make(map[string]*InventoryDevice),
+		dmiDevices: make(map[string]bool),
+	}
+
+	labelNames := append([]string{}, inventoryLabels...)
+	for _, label := range conf.ExtraLabels {
+		name := sanitizeMetricName(label)
+		if name != label || containsString(labelNames, name) {
+			logger.Warn("Ignoring inventory extra label [%s], not a valid or unique label name", label)
+			continue
+		}
+		inv.extraLabels = append(inv.extraLabels, name)
+		labelNames = append(labelNames, name)
+	}
+	inv.info = prometheus.NewDesc("kte_device_info", "Identities of an OLT or ONU as seen by VOLTHA, ONOS and the device manager", labelNames, nil)
+
+	if conf.File != "" {
+		devices, err := loadInventoryFile(conf.File)
+		if err != nil {
+			logger.Error("Cannot load the inventory file [%s]: %s", conf.File, err.Error())
+		}
+		for _, device := range devices {
+			if device.SerialNumber == "" {
+				logger.Warn("Ignoring inventory entry without serial number: %+v", *device)
+				continue
+			}
+			inv.static[device.SerialNumber] = device
+		}
+		logger.Info("Loaded [%d] devices from the inventory file [%s]", len(inv.static), conf.File)
+	}
+	return inv
+}
+
+// loadInventoryFile reads a YAML list of devices, or a CSV file whose
+// header names the columns after the YAML keys. Other CSV columns are
+// used as extra labels.
+func loadInventoryFile(path string) ([]*InventoryDevice, error) {
+	if strings.ToLower(filepath.Ext(path)) != ".csv" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var devices []*InventoryDevice
+		err = yaml.Unmarshal(data, &devices)
+		return devices, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	devices := make([]*InventoryDevice, 0, len(records)-1)
+	for line, record := range records[1:] {
+		if len(record) != len(header) {
+			return devices, fmt.Errorf("line %d has %d columns, %d expected", line+2, len(record), len(header))
+		}
+		device := &InventoryDevice{Labels: make(map[string]string)}
+		for i, column := range header {
+			switch value := strings.TrimSpace(record[i]); strings.TrimSpace(column) {
+			case "type":
+				device.Type = value
+			case "serial_number":
+				device.SerialNumber = value
+			case "device_id":
+				device.DeviceID = value
+			case "logical_device_id":
+				device.LogicalDeviceID = value
+			case "onos_device_id":
+				device.OnosDeviceID = value
+			case "dmi_device_uuid":
+				device.DmiDeviceUUID = value
+			case "olt_serial":
+				device.OltSerial = value
+			case "pon_id":
+				device.PonID = value
+			case "subscriber_id":
+				device.SubscriberID = value
+			default:
+				device.Labels[strings.TrimSpace(column)] = value
+			}
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+// learn applies the update to the learned device with the serial number
+func (inv *deviceInventory) learn(serial string, update func(*InventoryDevice)) {
+	if inv == nil || serial == "" {
+		return
+	}
+	inv.Lock()
+	defer inv.Unlock()
+
+	device, ok := inv.learned[serial]
+	if !ok {
+		device = &InventoryDevice{SerialNumber: serial, Labels: make(map[string]string)}
+		inv.learned[serial] = device
+	}
+	update(device)
+}
+
+// learnVolthaDevice records the VOLTHA identity of the device a KpiEvent2
+// group is about
+func (inv *deviceInventory) learnVolthaDevice(data *voltha.MetricInformation) {
+	title := data.GetMetadata().GetTitle()
+	if title == "ETHERNET_NNI" || title == "PON_OLT" {
+		inv.learn(data.GetMetadata().GetSerialNo(), func(device *InventoryDevice) {
+			device.Type = inventoryTypeOlt
+			device.DeviceID = data.GetMetadata().GetDeviceId()
+			device.LogicalDeviceID = data.GetMetadata().GetLogicalDeviceId()
+		})
+		return
+	}
+	// the hex encoding option only applies to the ONU serial numbers
+	inv.learn(utils.GetOnuSN(data.GetMetadata().GetSerialNo()), func(device *InventoryDevice) {
+		device.Type = inventoryTypeOnu
+		device.DeviceID = data.GetMetadata().GetDeviceId()
+		device.LogicalDeviceID = data.GetMetadata().GetLogicalDeviceId()
+		if ponID, _ := volthaOnuPonLabels(data.GetMetadata().GetContext()); ponID != "NA" {
+			device.PonID = ponID
+		}
+	})
+}
+
+// learnDmiDevice records a device seen on dm.metrics. The metrics carry no
+// serial number, the UUID is joined to the OLT with the same
+// dmi_device_uuid in the inventory file and reported on its own otherwise.
+func (inv *deviceInventory) learnDmiDevice(deviceUUID string) {
+	if inv == nil || deviceUUID == "" {
+		return
+	}
+	inv.Lock()
+	defer inv.Unlock()
+
+	inv.dmiDevices[deviceUUID] = true
+}
+
+// learnOnosDevice records the ONOS device an ONU is attached to
+func (inv *deviceInventory) learnOnosDevice(onuSerial string, onosDeviceID string) {
+	if onosDeviceID == "" {
+		return
+	}
+	inv.learn(onuSerial, func(device *InventoryDevice) {
+		device.Type = inventoryTypeOnu
+		device.OnosDeviceID = onosDeviceID
+	})
+}
+
+// devices returns the merged view of the static and learned devices
+func (inv *deviceInventory) devices() []*InventoryDevice {
+	inv.Lock()
+	defer inv.Unlock()
+
+	merged := make(map[string]*InventoryDevice)
+	for serial, static := range inv.static {
+		device := *static
+		device.Labels = make(map[string]string)
+		device.merge(static)
+		merged[serial] = &device
+	}
+	for serial, learned := range inv.learned {
+		if device, ok := merged[serial]; ok {
+			device.merge(learned)
+			continue
+		}
+		device := *learned
+		device.Labels = make(map[string]string)
+		device.merge(learned)
+		merged[serial] = &device
+	}
+
+	// the ONUs share the VOLTHA logical device and the ONOS device of
+	// their OLT, which joins the two views
+	olts := make(map[string]*InventoryDevice)
+	for _, device := range merged {
+		if device.Type == inventoryTypeOlt && device.LogicalDeviceID != "" {
+			olts[device.LogicalDeviceID] = device
+		}
+	}
+	for _, device := range merged {
+		if device.Type != inventoryTypeOnu {
+			continue
+		}
+		if olt, ok := olts[device.LogicalDeviceID]; ok {
+			if device.OltSerial == "" {
+				device.OltSerial = olt.SerialNumber
+			}
+			if olt.OnosDeviceID == "" {
+				olt.OnosDeviceID = device.OnosDeviceID
+			}
+		}
+	}
+
+	devices := make([]*InventoryDevice, 0, len(merged)+len(inv.dmiDevices))
+	known := make(map[string]bool)
+	for _, device := range merged {
+		known[device.DmiDeviceUUID] = true
+	}
+	for deviceUUID := range inv.dmiDevices {
+		if !known[deviceUUID] {
+			devices = append(devices, &InventoryDevice{Type: inventoryTypeOlt, DmiDeviceUUID: deviceUUID})
+		}
+	}
+	for _, device := range merged {
+		if device.SubscriberID == "" && device.Type == inventoryTypeOnu {
+			if sub := sadisSubscribers.lookup(device.SerialNumber, ""); sub != nil {
+				device.SubscriberID = sub.ID
+			}
+		}
+		devices = append(devices, device)
+	}
+	return devices
+}
+
+// Describe implements prometheus.Collector
+func (inv *deviceInventory) Describe(ch chan<- *prometheus.Desc) {
+	ch <- inv.info
+}
+
+// Collect implements prometheus.Collector
+func (inv *deviceInventory) Collect(ch chan<- prometheus.Metric) {
+	for _, device := range inv.devices() {
+		ch <- prometheus.MustNewConstMetric(inv.info, prometheus.GaugeValue, 1, device.labelValues(inv.extraLabels)...)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"gerrit.opencord.org/kafka-topic-exporter/utils"
+	"github.com/opencord/voltha-protos/v5/go/voltha"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInventoryJoin(t *testing.T) {
+	logger.Setup("", "ERROR")
+	utils.OnuSNhex = true
+	defer func() { utils.OnuSNhex = false }()
+
+	dir, err := ioutil.TempDir("", "kte-inventory")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "inventory.yaml")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(`
+- type: olt
+  serial_number: EC1721000208
+  dmi_device_uuid: 5a23ee76-a5d8-11eb-b2e2-0242ac110002
+`), 0644))
+
+	inv := newDeviceInventory(InventoryInfo{File: file})
+	inv.learnVolthaDevice(&voltha.MetricInformation{Metadata: &voltha.MetricMetaData{
+		Title:           "PON_OLT",
+		LogicalDeviceId: "of:0000000000000001",
+		SerialNo:        "EC1721000208",
+		DeviceId:        "olt-1",
+	}})
+	inv.learnVolthaDevice(&voltha.MetricInformation{Metadata: &voltha.MetricMetaData{
+		Title:           "PON_Optical",
+		LogicalDeviceId: "of:0000000000000001",
+		SerialNo:        "BBSM00000001",
+		DeviceId:        "onu-1",
+		Context:         map[string]string{"intf_id": "0"},
+	}})
+	inv.learnDmiDevice("5a23ee76-a5d8-11eb-b2e2-0242ac110002")
+	inv.learnDmiDevice("7b3c9e10-a5d8-11eb-b2e2-0242ac110002")
+
+	devices := make(map[string]*InventoryDevice)
+	for _, device := range inv.devices() {
+		devices[device.SerialNumber+"/"+device.DmiDeviceUUID] = device
+	}
+	assert.Len(t, devices, 3)
+
+	// the OLT serial number is not hex encoded, the DMI view is joined
+	// through the inventory file
+	olt := devices["EC1721000208/5a23ee76-a5d8-11eb-b2e2-0242ac110002"]
+	if assert.NotNil(t, olt) {
+		assert.Equal(t, "olt-1", olt.DeviceID)
+		assert.Equal(t, "of:0000000000000001", olt.LogicalDeviceID)
+	}
+
+	onu := devices["4242534D00000001/"]
+	if assert.NotNil(t, onu) {
+		assert.Equal(t, inventoryTypeOnu, onu.Type)
+		assert.Equal(t, "EC1721000208", onu.OltSerial)
+		assert.Equal(t, "0", onu.PonID)
+	}
+
+	// a device manager device no serial number is known for
+	unknown := devices["/7b3c9e10-a5d8-11eb-b2e2-0242ac110002"]
+	if assert.NotNil(t, unknown) {
+		assert.Equal(t, inventoryTypeOlt, unknown.Type)
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/Shopify/sarama"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	kafkaOutputJSON     = "json"
+	kafkaOutputProtobuf = "protobuf"
+)
+
+var (
+	kafkaOutputPublished = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kte_kafka_output_published_total",
+			Help: "Normalized metrics published to the output topic",
+		})
+	kafkaOutputFailed = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kte_kafka_output_failed_total",
+			Help: "Normalized metrics that could not be published to the output topic",
+		})
+	kafkaOutputDropped = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kte_kafka_output_dropped_total",
+			Help: "Normalized metrics dropped as the producer buffer was full",
+		})
+)
+
+// normalizedMetric is the single schema of the output topic, whatever the
+// topic and encoding the KPI was received with
+type normalizedMetric struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+	// milliseconds since the epoch
+	Timestamp   int64  `json:"timestamp"`
+	SourceTopic string `json:"source_topic"`
+}
+
+// kafkaPublisher re-publishes the samples of the topic handlers to an
+// output topic
+type kafkaPublisher struct {
+	conf     KafkaOutputInfo
+	producer sarama.AsyncProducer
+}
+
+func newKafkaPublisher(conf KafkaOutputInfo, broker BrokerInfo) (*kafkaPublisher, error) {
+	switch conf.Format {
+	case "":
+		conf.Format = kafkaOutputJSON
+	case kafkaOutputJSON, kafkaOutputProtobuf:
+	default:
+		return nil, fmt.Errorf("unknown format [%s], expected json or protobuf", conf.Format)
+	}
+	if conf.Topic == "" {
+		return nil, fmt.Errorf("the output topic is needed")
+	}
+	if conf.Host == "" {
+		conf.Host = broker.Host
+	}
+	if conf.Partitions == 0 {
+		conf.Partitions = cDefaultPartitions
+	}
+	if conf.Replicas == 0 {
+		conf.Replicas = cDefaultReplicas
+	}
+
+	config := sarama.NewConfig()
+	config.Metadata.AllowAutoTopicCreation = false
+	config.Producer.Return.Errors = true
+
+	clusterAdmin, err := sarama.NewClusterAdmin([]string{conf.Host}, config)
+	if err != nil {
+		return nil, err
+	}
+	defer clusterAdmin.Close()
+	if err := createTopic(clusterAdmin, conf.Topic, conf.Partitions, conf.Replicas); err != nil {
+		return nil, err
+	}
+
+	producer, err := sarama.NewAsyncProducer([]string{conf.Host}, config)
+	if err != nil {
+		return nil, err
+	}
+	return &kafkaPublisher{conf: conf, producer: producer}, nil
+}
+
+// run logs the messages the producer failed to deliver
+func (p *kafkaPublisher) run() {
+	for err := range p.producer.Errors() {
+		logger.Error("Cannot publish to [%s]: %s", p.conf.Topic, err.Error())
+		kafkaOutputFailed.Inc()
+	}
+}
+
+// Write implements Sink, publishing the sample keyed by the device so that
+// the metrics of a device stay ordered. The sample is dropped rather than
+// holding the consumers back when the producer buffer is full.
+func (p *kafkaPublisher) Write(s *Sample) {
+	metric, ok := p.normalize(s)
+	if !ok {
+		return
+	}
+	var value []byte
+	if p.conf.Format == kafkaOutputProtobuf {
+		value = encodeNormalizedMetric(metric)
+	} else {
+		var err error
+		if value, err = json.Marshal(metric); err != nil {
+			logger.Error("Cannot encode [%s]: %s", metric.Name, err.Error())
+			kafkaOutputFailed.Inc()
+			return
+		}
+	}
+	message := &sarama.ProducerMessage{
+		Topic: p.conf.Topic,
+		Value: sarama.ByteEncoder(value),
+	}
+	if key := metric.Labels["device_id"] + metric.Labels["deviceuuid"]; key != "" {
+		message.Key = sarama.StringEncoder(key)
+	}
+
+	select {
+	case p.producer.Input() <- message:
+		kafkaOutputPublished.Inc()
+	default:
+		kafkaOutputDropped.Inc()
+	}
+}
+
+// Delete implements Sink, the output topic only carries values
+func (p *kafkaPublisher) Delete(name string, labelValues []string) {}
+
+// normalize turns a sample into an output message, the empty labels being
+// left out
+func (p *kafkaPublisher) normalize(s *Sample) (*normalizedMetric, bool) {
+	// JSON has no NaN nor infinity
+	if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+		return nil, false
+	}
+
+	labels := make(map[string]string, len(s.LabelNames))
+	for i, name := range s.LabelNames {
+		if i < len(s.LabelValues) && s.LabelValues[i] != "" {
+			labels[name] = s.LabelValues[i]
+		}
+	}
+	return &normalizedMetric{
+		Name:        s.Name,
+		Labels:      labels,
+		Value:       s.Value,
+		Timestamp:   s.Timestamp.UnixNano() / int64(time.Millisecond),
+		SourceTopic: s.Source,
+	}, true
+}
+
+// encodeNormalizedMetric encodes the protobuf form of the output messages:
+//
+//	message Metric {
+//	  string name = 1;
+//	  map<string, string> labels = 2;
+//	  double value = 3;
+//	  int64 timestamp = 4;
+//	  string source_topic = 5;
+//	}
+func encodeNormalizedMetric(metric *normalizedMetric) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, metric.Name)
+
+	names := make([]string, 0, len(metric.Labels))
+	for name := range metric.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var entry []byte
+		entry = protowire.AppendTag(entry, 1, protowire.BytesType)
+		entry = protowire.AppendString(entry, name)
+		entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+		entry = protowire.AppendString(entry, metric.Labels[name])
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, entry)
+	}
+
+	b = protowire.AppendTag(b, 3, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(metric.Value))
+	b = protowire.AppendTag(b, 4, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(metric.Timestamp))
+	b = protowire.AppendTag(b, 5, protowire.BytesType)
+	return protowire.AppendString(b, metric.SourceTopic)
+}
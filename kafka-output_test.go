@@ -0,0 +1,124 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/Shopify/sarama"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAsyncProducer buffers the produced messages
+type fakeAsyncProducer struct {
+	sarama.AsyncProducer
+	input chan *sarama.ProducerMessage
+}
+
+func (p *fakeAsyncProducer) Input() chan<- *sarama.ProducerMessage {
+	return p.input
+}
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	m := &dto.Metric{}
+	assert.NoError(t, counter.Write(m))
+	return m.GetCounter().GetValue()
+}
+
+func TestKafkaPublisherJSON(t *testing.T) {
+	logger.Setup("", "ERROR")
+	vec := newCounterVec(prometheus.CounterOpts{Name: "voltha_kte_test_output_total", Help: "test"}, []string{"device_id", "port"})
+	defer func() {
+		metricFamilies.Lock()
+		delete(metricFamilies.vecs, "voltha_kte_test_output_total")
+		metricFamilies.Unlock()
+	}()
+
+	producer := &fakeAsyncProducer{input: make(chan *sarama.ProducerMessage, 2)}
+	publisher := &kafkaPublisher{
+		conf:     KafkaOutputInfo{Topic: "kte.metrics", Format: kafkaOutputJSON},
+		producer: producer,
+	}
+	namespaced, err := newNamespaceGatherer(metricsRegistry, TargetInfo{Namespace: "lab", ConstLabels: map[string]string{"site": "lab1"}})
+	assert.NoError(t, err)
+	defer func(sinks []Sink, wrappers []func(prometheus.Gatherer) prometheus.Gatherer) {
+		metricSinks, gathererWrappers = sinks, wrappers
+	}(metricSinks, gathererWrappers)
+	metricSinks = []Sink{prometheusSink{}, pipelineSink{publisher}}
+	gathererWrappers = []func(prometheus.Gatherer) prometheus.Gatherer{namespaced.wrap}
+
+	// the counter total is published rather than the increment
+	vec.WithLabelValues("onu-1", "").Add(3)
+	vec.WithLabelValues("onu-1", "").Add(4)
+	dropped := counterValue(t, kafkaOutputDropped)
+	vec.WithLabelValues("onu-1", "").Inc()
+	assert.Equal(t, dropped+1, counterValue(t, kafkaOutputDropped))
+
+	var values []float64
+	for i := 0; i < 2; i++ {
+		message := <-producer.input
+		assert.Equal(t, "kte.metrics", message.Topic)
+		assert.Equal(t, sarama.StringEncoder("onu-1"), message.Key)
+
+		encoded, err := message.Value.Encode()
+		assert.NoError(t, err)
+		metric := normalizedMetric{}
+		assert.NoError(t, json.Unmarshal(encoded, &metric))
+		assert.Equal(t, "lab_voltha_kte_test_output_total", metric.Name)
+		assert.Equal(t, map[string]string{"device_id": "onu-1", "site": "lab1"}, metric.Labels)
+		assert.Equal(t, volthaEventsTopic, metric.SourceTopic)
+		assert.InDelta(t, time.Now().UnixNano()/int64(time.Millisecond), metric.Timestamp, float64(time.Minute/time.Millisecond))
+		values = append(values, metric.Value)
+	}
+	assert.Equal(t, []float64{3, 7}, values)
+}
+
+func TestEncodeNormalizedMetric(t *testing.T) {
+	encoded := encodeNormalizedMetric(&normalizedMetric{
+		Name:        "onos_rx_bytes_total",
+		Labels:      map[string]string{"port_id": "16", "device_id": "of:0000000000000001"},
+		Value:       1024.5,
+		Timestamp:   1636106400000,
+		SourceTopic: "onos.kpis",
+	})
+
+	fields := decodeOtlpFields(t, encoded)
+	assert.Equal(t, "onos_rx_bytes_total", string(fields[1][0].bytes))
+	labels := make(map[string]string)
+	for _, entry := range fields[2] {
+		pair := decodeOtlpFields(t, entry.bytes)
+		labels[string(pair[1][0].bytes)] = string(pair[2][0].bytes)
+	}
+	assert.Equal(t, map[string]string{"port_id": "16", "device_id": "of:0000000000000001"}, labels)
+	assert.Equal(t, 1024.5, math.Float64frombits(fields[3][0].number))
+	assert.Equal(t, uint64(1636106400000), fields[4][0].number)
+	assert.Equal(t, "onos.kpis", string(fields[5][0].bytes))
+}
+
+func TestMetricSourceTopic(t *testing.T) {
+	assert.Equal(t, volthaEventsTopic, metricSourceTopic("voltha_onu_temperature"))
+	assert.Equal(t, "authentication.events", metricSourceTopic("onos_aaa_subscriber_state"))
+	assert.Equal(t, "onos.events", metricSourceTopic("onos_port_info"))
+	assert.Equal(t, "onos.kpis", metricSourceTopic("onos_rx_bytes_total"))
+	assert.Equal(t, "dm.metrics", metricSourceTopic("olt_device_fan_speed"))
+	assert.Equal(t, "importer", metricSourceTopic("device_temperature"))
+	assert.Equal(t, "", metricSourceTopic("kte_kafka_output_dropped_total"))
+}
@@ -0,0 +1,266 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	cDefaultLegacyPushInterval = 60 * time.Second
+	cDefaultLegacyPushTimeout  = 10 * time.Second
+	// keeps the StatsD datagrams under the usual network MTU
+	cStatsdMaxPacketSize = 1432
+)
+
+const (
+	graphitePush = "graphite"
+	statsdPush   = "statsd"
+)
+
+// {name} is the metric name, {<label>} the value of a label
+var legacyPathPlaceholder = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+
+// legacyPusher periodically flushes the gathered series to a Graphite
+// plaintext or a (Dog)StatsD endpoint
+type legacyPusher struct {
+	kind     string
+	conf     LegacyPushInfo
+	gatherer prometheus.Gatherer
+	// labels consumed by the path template
+	pathLabels map[string]bool
+}
+
+func newLegacyPusher(kind string, conf LegacyPushInfo, gatherer prometheus.Gatherer) (*legacyPusher, error) {
+	if conf.Address == "" {
+		return nil, fmt.Errorf("the %s address is needed", kind)
+	}
+	if conf.Protocol == "" {
+		if kind == statsdPush {
+			conf.Protocol = "udp"
+		} else {
+			conf.Protocol = "tcp"
+		}
+	}
+	if conf.Protocol != "tcp" && conf.Protocol != "udp" {
+		return nil, fmt.Errorf("unknown %s protocol [%s], expected tcp or udp", kind, conf.Protocol)
+	}
+	if conf.Template == "" {
+		conf.Template = "{name}"
+	}
+	if conf.Interval == 0 {
+		conf.Interval = cDefaultLegacyPushInterval
+	}
+	if conf.Timeout == 0 {
+		conf.Timeout = cDefaultLegacyPushTimeout
+	}
+
+	p := &legacyPusher{
+		kind:       kind,
+		conf:       conf,
+		gatherer:   gatherer,
+		pathLabels: make(map[string]bool),
+	}
+	for _, match := range legacyPathPlaceholder.FindAllStringSubmatch(conf.Template, -1) {
+		p.pathLabels[match[1]] = true
+	}
+	return p, nil
+}
+
+func (p *legacyPusher) run() {
+	ticker := time.NewTicker(p.conf.Interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := p.push(); err != nil {
+			logger.Error("Cannot push the metrics to %s [%s]: %s", p.kind, p.conf.Address, err.Error())
+		}
+	}
+}
+
+// push sends the current value of every gathered series
+func (p *legacyPusher) push() error {
+	mfs, err := p.gatherer.Gather()
+	if err != nil {
+		logger.Warn("Errors gathering the metrics for %s: %s", p.kind, err.Error())
+	}
+	now := time.Now()
+	var lines [][]byte
+	for _, mf := range mfs {
+		for _, s := range familySamples(mf, 0) {
+			if math.IsNaN(s.value) || math.IsInf(s.value, 0) {
+				continue
+			}
+			if p.kind == statsdPush {
+				lines = append(lines, p.statsdLine(s))
+			} else {
+				lines = append(lines, p.graphiteLine(s, now))
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	conn, err := net.DialTimeout(p.conf.Protocol, p.conf.Address, p.conf.Timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetWriteDeadline(time.Now().Add(p.conf.Timeout)); err != nil {
+		return err
+	}
+
+	if p.conf.Protocol == "tcp" {
+		_, err = conn.Write(bytes.Join(lines, nil))
+		return err
+	}
+	// a datagram per packet of whole lines
+	var packet []byte
+	for _, line := range lines {
+		if len(packet) > 0 && len(packet)+len(line) > cStatsdMaxPacketSize {
+			if _, err := conn.Write(packet); err != nil {
+				return err
+			}
+			packet = packet[:0]
+		}
+		packet = append(packet, line...)
+	}
+	_, err = conn.Write(packet)
+	return err
+}
+
+// path expands the template, the placeholders of labels a series does not
+// have are left empty and the empty path components dropped. Without tags
+// the labels the template does not consume are appended as name.value
+// components, keeping the series of a metric apart.
+func (p *legacyPusher) path(s *sample) string {
+	path := legacyPathPlaceholder.ReplaceAllStringFunc(p.conf.Template, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		if name == "name" {
+			return s.name
+		}
+		for _, pair := range s.labels {
+			if pair.GetName() == name {
+				return legacyPathComponent(pair.GetValue())
+			}
+		}
+		return ""
+	})
+	components := strings.Split(path, ".")
+	if !p.conf.Tags {
+		for _, tag := range p.tags(s) {
+			components = append(components, tag.GetName(), legacyPathComponent(tag.GetValue()))
+		}
+	}
+	kept := components[:0]
+	for _, component := range components {
+		if component != "" {
+			kept = append(kept, component)
+		}
+	}
+	return strings.Join(kept, ".")
+}
+
+// tags are the labels the template does not consume
+func (p *legacyPusher) tags(s *sample) []*dto.LabelPair {
+	var tags []*dto.LabelPair
+	for _, pair := range s.labels {
+		if !p.pathLabels[pair.GetName()] && pair.GetValue() != "" {
+			tags = append(tags, pair)
+		}
+	}
+	return tags
+}
+
+// graphiteLine formats a plaintext line, the other labels being Graphite
+// tags when enabled
+//
+//	path[;tag=value...] value timestamp
+func (p *legacyPusher) graphiteLine(s *sample, now time.Time) []byte {
+	b := []byte(p.path(s))
+	if p.conf.Tags {
+		for _, tag := range p.tags(s) {
+			b = append(b, ';')
+			b = append(b, tag.GetName()...)
+			b = append(b, '=')
+			b = append(b, legacyTagValue(tag.GetValue(), ";~")...)
+		}
+	}
+	b = append(b, ' ')
+	b = strconv.AppendFloat(b, s.value, 'g', -1, 64)
+	b = append(b, ' ')
+	ts := now.Unix()
+	if s.timestamp != 0 {
+		ts = s.timestamp / 1000
+	}
+	b = strconv.AppendInt(b, ts, 10)
+	return append(b, '\n')
+}
+
+// statsdLine formats the series as a gauge, the cumulative counters
+// included, the other labels being DogStatsD tags when enabled
+//
+//	path:value|g[|#tag:value,...]
+func (p *legacyPusher) statsdLine(s *sample) []byte {
+	b := []byte(p.path(s))
+	b = append(b, ':')
+	b = strconv.AppendFloat(b, s.value, 'g', -1, 64)
+	b = append(b, "|g"...)
+	if p.conf.Tags {
+		for i, tag := range p.tags(s) {
+			if i == 0 {
+				b = append(b, "|#"...)
+			} else {
+				b = append(b, ',')
+			}
+			b = append(b, tag.GetName()...)
+			b = append(b, ':')
+			b = append(b, legacyTagValue(tag.GetValue(), ",|#")...)
+		}
+	}
+	return append(b, '\n')
+}
+
+// legacyPathComponent keeps a label value from splitting the dotted path
+func legacyPathComponent(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', ' ', '/', ';', ':', '|':
+			return '_'
+		}
+		return r
+	}, value)
+}
+
+// legacyTagValue replaces the characters the protocol reserves in tags
+func legacyTagValue(value string, reserved string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || strings.ContainsRune(reserved, r) {
+			return '_'
+		}
+		return r
+	}, value)
+}
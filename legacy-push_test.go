@@ -0,0 +1,72 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func legacySample(value float64, labels ...string) *sample {
+	s := &sample{name: "voltha_onu_rx_bytes_total", value: value}
+	for i := 0; i < len(labels); i += 2 {
+		s.labels = append(s.labels, &dto.LabelPair{Name: proto.String(labels[i]), Value: proto.String(labels[i+1])})
+	}
+	return s
+}
+
+func TestLegacyPushPath(t *testing.T) {
+	onu1 := legacySample(1, "device_id", "onu-1", "port", "", "serial_number", "BBSM.00000001")
+	onu2 := legacySample(2, "device_id", "onu-2", "port", "16", "serial_number", "BBSM.00000002")
+
+	// the default template keeps the series apart through their labels
+	p, err := newLegacyPusher(graphitePush, LegacyPushInfo{Address: "localhost:2003"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "voltha_onu_rx_bytes_total.device_id.onu-1.serial_number.BBSM_00000001", p.path(onu1))
+	assert.Equal(t, "voltha_onu_rx_bytes_total.device_id.onu-2.port.16.serial_number.BBSM_00000002", p.path(onu2))
+
+	// the labels of the template are not repeated
+	p, err = newLegacyPusher(graphitePush, LegacyPushInfo{Address: "localhost:2003", Template: "kte.{serial_number}.{olt}.{name}"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "kte.BBSM_00000001.voltha_onu_rx_bytes_total.device_id.onu-1", p.path(onu1))
+
+	// with tags the path is the template only
+	p, err = newLegacyPusher(graphitePush, LegacyPushInfo{Address: "localhost:2003", Template: "kte.{serial_number}.{name}", Tags: true}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "kte.BBSM_00000002.voltha_onu_rx_bytes_total", p.path(onu2))
+}
+
+func TestLegacyPushLines(t *testing.T) {
+	s := legacySample(1024.5, "device_id", "onu 1", "port", "16", "serial_number", "BBSM00000001")
+	now := time.Unix(1636106400, 0)
+
+	p, err := newLegacyPusher(graphitePush, LegacyPushInfo{Address: "localhost:2003", Template: "kte.{serial_number}.{name}", Tags: true}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "kte.BBSM00000001.voltha_onu_rx_bytes_total;device_id=onu_1;port=16 1024.5 1636106400\n", string(p.graphiteLine(s, now)))
+	s.timestamp = 1636106500000
+	assert.Equal(t, "kte.BBSM00000001.voltha_onu_rx_bytes_total;device_id=onu_1;port=16 1024.5 1636106500\n", string(p.graphiteLine(s, now)))
+
+	p, err = newLegacyPusher(statsdPush, LegacyPushInfo{Address: "localhost:8125", Template: "kte.{serial_number}.{name}", Tags: true}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "kte.BBSM00000001.voltha_onu_rx_bytes_total:1024.5|g|#device_id:onu_1,port:16\n", string(p.statsdLine(s)))
+
+	p, err = newLegacyPusher(statsdPush, LegacyPushInfo{Address: "localhost:8125"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "voltha_onu_rx_bytes_total.device_id.onu_1.port.16.serial_number.BBSM00000001:1024.5|g\n", string(p.statsdLine(s)))
+}
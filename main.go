@@ -16,9 +16,11 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 	"sync"
@@ -36,8 +38,30 @@ const (
 	consumerGroup      = "kte_grp"
 	cDefaultPartitions = 1
 	cDefaultReplicas   = 1
+	// this file path is configmap mounted in pod yaml
+	cDefaultConfigFile = "/etc/config/conf.yaml"
+)
+
+// metricsRegistry holds the exported metrics, rather than the global
+// default registry. metricsRegisterer registers them, possibly filtering
+// them, and metricsGatherer is what the /metrics endpoint serves.
+var (
+	metricsRegistry                         = prometheus.NewRegistry()
+	metricsRegisterer prometheus.Registerer = metricsRegistry
+	metricsGatherer   prometheus.Gatherer   = metricsRegistry
+	// gathererWrappers join and rewrite the labels of the gathered series,
+	// in order, from the innermost
+	gathererWrappers []func(prometheus.Gatherer) prometheus.Gatherer
 )
 
+// wrapGatherer applies the gathererWrappers to a gatherer of the registry
+func wrapGatherer(g prometheus.Gatherer) prometheus.Gatherer {
+	for _, wrap := range gathererWrappers {
+		g = wrap(g)
+	}
+	return g
+}
+
 func kafkaInit(broker BrokerInfo) {
 	config := sarama.NewConfig()
 
@@ -94,7 +118,7 @@ func kafkaInit(broker BrokerInfo) {
 
 	wg.Add(1)
 
-	go topicListener(ctx, topics, consumer, wg)
+	go topicListener(ctx, topics, consumer, &wg, &Consumer{HandleFunc: export})
 
 	wg.Wait()
 	cancel()
@@ -127,158 +151,223 @@ func runServer(target TargetInfo) {
 		target.Port = 8080
 	}
 	logger.Debug("Starting HTTP Server on %d port", target.Port)
-	http.Handle("/metrics", promhttp.Handler())
+	http.Handle("/metrics", metricsHandler())
 	err := http.ListenAndServe(":"+strconv.Itoa(target.Port), nil)
 	if err != nil {
 		logger.Error("HTTP Server Error: %s", err.Error())
 	}
 }
 
-func init() {
+// metricsHandler serves the metrics in the format the scraper asks for,
+// OpenMetrics included. A series failing to gather must not hide all the
+// others.
+func metricsHandler() http.Handler {
+	return promhttp.InstrumentMetricHandler(
+		metricsRegistry, promhttp.HandlerFor(metricsGatherer, promhttp.HandlerOpts{
+			ErrorHandling:     promhttp.ContinueOnError,
+			EnableOpenMetrics: true,
+		}),
+	)
+}
+
+func registerMetrics(registerer prometheus.Registerer) {
 	// register metrics within Prometheus
-	prometheus.MustRegister(volthaOltTxBytesTotal)
-	prometheus.MustRegister(volthaOltRxBytesTotal)
-	prometheus.MustRegister(volthaOltTxPacketsTotal)
-	prometheus.MustRegister(volthaOltRxPacketsTotal)
-	prometheus.MustRegister(volthaOltTxErrorPacketsTotal)
-	prometheus.MustRegister(volthaOltRxErrorPacketsTotal)
-	prometheus.MustRegister(volthaOltTxBroadcastPacketsTotal)
-	prometheus.MustRegister(volthaOltTxUnicastPacketsTotal)
-	prometheus.MustRegister(volthaOltTxMulticastPacketsTotal)
-	prometheus.MustRegister(volthaOltRxBroadcastPacketsTotal)
-	prometheus.MustRegister(volthaOltRxUnicastPacketsTotal)
-	prometheus.MustRegister(volthaOltRxMulticastPacketsTotal)
-
-	prometheus.MustRegister(VolthaOnuLaserBiasCurrent)
-	prometheus.MustRegister(volthaOnuTemperature)
-	prometheus.MustRegister(VolthaOnuPowerFeedVoltage)
-	prometheus.MustRegister(VolthaOnuMeanOpticalLaunchPower)
-	prometheus.MustRegister(VolthaOnuReceivedOpticalPower)
-	prometheus.MustRegister(VolthaOnuTransmtOpticalPower)
-
-	prometheus.MustRegister(volthaOnuFecCorrectedCodewordsTotal)
-	prometheus.MustRegister(volthaOnuFecCodewordsTotal)
-	prometheus.MustRegister(volthaOnuFecCorrectedBytesTotal)
-	prometheus.MustRegister(volthaOnuFecSecondsTotal)
-	prometheus.MustRegister(volthaOnuFecUncorrectablewordsTotal)
-
-	prometheus.MustRegister(volthaEthernetUniSingleCollisionTotal)
-	prometheus.MustRegister(volthaEthernetUniMacLayerTramsmitErrorTotal)
-	prometheus.MustRegister(volthaEthernetUniMultiCollisionTotal)
-	prometheus.MustRegister(volthaEthernetUniFramestooLongTotal)
-	prometheus.MustRegister(volthaEthernetUniAlignmentErrorTotal)
-	prometheus.MustRegister(volthaEthernetUniCarrierErrorTotal)
-	prometheus.MustRegister(volthaEthernetUniExcessiveCollisionErrorTotal)
-	prometheus.MustRegister(volthaEthernetUniDeferredTxTotal)
-	prometheus.MustRegister(volthaEthernetUniLateCollisionTotal)
-	prometheus.MustRegister(volthaEthernetUniBufferOverflowsRxErrorTotal)
-	prometheus.MustRegister(volthaEthernetUniFcsErrorTotal)
-	prometheus.MustRegister(volthaEthernetUniSqeErrorTotal)
-	prometheus.MustRegister(volthaEthernetUniBufferOverflowsTxErrorTotal)
-
-	prometheus.MustRegister(volthaOnuBridgePortRxBytesTotal)
-	prometheus.MustRegister(volthaOnuBridgePortRxPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePort_64octetRxPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePort_65_127_octetRxPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePort_128_255_octetRxPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePort_256_511_octetRxPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePort_512_1023_octetRxPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePort_1024_1518_octetRxPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePortRxMulticastPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePortRxBroadcastPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePortRxOversizePacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePortRxCrcErrorPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePortRxUndersizePacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePortRxDropEventsTotal)
-
-	prometheus.MustRegister(volthaOnuBridgePortTxBytesTotal)
-	prometheus.MustRegister(volthaOnuBridgePortTxPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePort_64octetTxPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePort_65_127_octetTxPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePort_128_255_octetTxPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePort_256_511_octetTxPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePort_512_1023_octetTxPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePort_1024_1518_octetTxPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePortTxMulticastPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePortTxBroadcastPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePortTxOversizePacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePortTxCrcErrorPacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePortTxUndersizePacketsTotal)
-	prometheus.MustRegister(volthaOnuBridgePortTxDropEventsTotal)
-
-	prometheus.MustRegister(onosTxBytesTotal)
-	prometheus.MustRegister(onosRxBytesTotal)
-	prometheus.MustRegister(onosTxPacketsTotal)
-	prometheus.MustRegister(onosRxPacketsTotal)
-	prometheus.MustRegister(onosTxDropPacketsTotal)
-	prometheus.MustRegister(onosRxDropPacketsTotal)
-
-	prometheus.MustRegister(onosaaaRxAcceptResponses)
-	prometheus.MustRegister(onosaaaRxRejectResponses)
-	prometheus.MustRegister(onosaaaRxChallengeResponses)
-	prometheus.MustRegister(onosaaaTxAccessRequests)
-	prometheus.MustRegister(onosaaaRxInvalidValidators)
-	prometheus.MustRegister(onosaaaRxUnknownType)
-	prometheus.MustRegister(onosaaaPendingRequests)
-	prometheus.MustRegister(onosaaaRxDroppedResponses)
-	prometheus.MustRegister(onosaaaRxMalformedResponses)
-	prometheus.MustRegister(onosaaaRxUnknownserver)
-	prometheus.MustRegister(onosaaaRequestRttMillis)
-	prometheus.MustRegister(onosaaaRequestReTx)
-
-	prometheus.MustRegister(onosBngUpTxBytes)
-	prometheus.MustRegister(onosBngUpTxPackets)
-	prometheus.MustRegister(onosBngUpDropBytes)
-	prometheus.MustRegister(onosBngUpDropPackets)
-	prometheus.MustRegister(onosBngControlPackets)
-	prometheus.MustRegister(onosBngDownRxBytes)
-	prometheus.MustRegister(onosBngDownRxPackets)
-	prometheus.MustRegister(onosBngDownTxBytes)
-	prometheus.MustRegister(onosBngDownTxPackets)
-	prometheus.MustRegister(onosBngDownDropPackets)
-	prometheus.MustRegister(onosBngDownDropBytes)
-
-	prometheus.MustRegister(deviceLaserBiasCurrent)
-	prometheus.MustRegister(deviceTemperature)
-	prometheus.MustRegister(deviceTxPower)
-	prometheus.MustRegister(deviceVoltage)
-
-	prometheus.MustRegister(onosaaaRxEapolLogoff)
-	prometheus.MustRegister(onosaaaTxEapolResIdentityMsg)
-	prometheus.MustRegister(onosaaaTxAuthSuccess)
-	prometheus.MustRegister(onosaaaTxAuthFailure)
-	prometheus.MustRegister(onosaaaTxStartReq)
-	prometheus.MustRegister(onosaaaEapPktTxAuthChooseEap)
-	prometheus.MustRegister(onosaaaTxRespnotNak)
-
-	prometheus.MustRegister(onosaaaEapolFramesTx)
-	prometheus.MustRegister(onosaaaAuthStateIdle)
-	prometheus.MustRegister(onosaaaRequestIdFramesTx)
-	prometheus.MustRegister(onosaaaRequestEapFramesTx)
-	prometheus.MustRegister(onosaaaInvalidPktType)
-	prometheus.MustRegister(onosaaaInvalidBodyLength)
-	prometheus.MustRegister(onosaaaValidEapolFramesRx)
-	prometheus.MustRegister(onosaaaPendingResSupplicant)
-	prometheus.MustRegister(onosaaaRxResIdEapFrames)
+	registerer.MustRegister(volthaOltTxBytesTotal)
+	registerer.MustRegister(volthaOltRxBytesTotal)
+	registerer.MustRegister(volthaOltTxPacketsTotal)
+	registerer.MustRegister(volthaOltRxPacketsTotal)
+	registerer.MustRegister(volthaOltTxErrorPacketsTotal)
+	registerer.MustRegister(volthaOltRxErrorPacketsTotal)
+	registerer.MustRegister(volthaOltTxBroadcastPacketsTotal)
+	registerer.MustRegister(volthaOltTxUnicastPacketsTotal)
+	registerer.MustRegister(volthaOltTxMulticastPacketsTotal)
+	registerer.MustRegister(volthaOltRxBroadcastPacketsTotal)
+	registerer.MustRegister(volthaOltRxUnicastPacketsTotal)
+	registerer.MustRegister(volthaOltRxMulticastPacketsTotal)
+
+	registerer.MustRegister(VolthaOnuLaserBiasCurrent)
+	registerer.MustRegister(volthaOnuTemperature)
+	registerer.MustRegister(VolthaOnuPowerFeedVoltage)
+	registerer.MustRegister(VolthaOnuMeanOpticalLaunchPower)
+	registerer.MustRegister(VolthaOnuReceivedOpticalPower)
+	registerer.MustRegister(VolthaOnuTransmtOpticalPower)
+
+	registerer.MustRegister(volthaOnuFecCorrectedCodewordsTotal)
+	registerer.MustRegister(volthaOnuFecCodewordsTotal)
+	registerer.MustRegister(volthaOnuFecCorrectedBytesTotal)
+	registerer.MustRegister(volthaOnuFecSecondsTotal)
+	registerer.MustRegister(volthaOnuFecUncorrectablewordsTotal)
+
+	registerer.MustRegister(volthaEthernetUniSingleCollisionTotal)
+	registerer.MustRegister(volthaEthernetUniMacLayerTramsmitErrorTotal)
+	registerer.MustRegister(volthaEthernetUniMultiCollisionTotal)
+	registerer.MustRegister(volthaEthernetUniFramestooLongTotal)
+	registerer.MustRegister(volthaEthernetUniAlignmentErrorTotal)
+	registerer.MustRegister(volthaEthernetUniCarrierErrorTotal)
+	registerer.MustRegister(volthaEthernetUniExcessiveCollisionErrorTotal)
+	registerer.MustRegister(volthaEthernetUniDeferredTxTotal)
+	registerer.MustRegister(volthaEthernetUniLateCollisionTotal)
+	registerer.MustRegister(volthaEthernetUniBufferOverflowsRxErrorTotal)
+	registerer.MustRegister(volthaEthernetUniFcsErrorTotal)
+	registerer.MustRegister(volthaEthernetUniSqeErrorTotal)
+	registerer.MustRegister(volthaEthernetUniBufferOverflowsTxErrorTotal)
+
+	registerer.MustRegister(volthaOnuUniOperState)
+	registerer.MustRegister(volthaOnuUniAdminState)
+	registerer.MustRegister(volthaOnuUniInfo)
+
+	registerer.MustRegister(volthaOnuEthFrameExtDropEvents)
+	registerer.MustRegister(volthaOnuEthFrameExtOctets)
+	registerer.MustRegister(volthaOnuEthFrameExtFrames)
+	registerer.MustRegister(volthaOnuEthFrameExtBroadcastFrames)
+	registerer.MustRegister(volthaOnuEthFrameExtMulticastFrames)
+	registerer.MustRegister(volthaOnuEthFrameExtCrcErroredFrames)
+	registerer.MustRegister(volthaOnuEthFrameExtUndersizeFrames)
+	registerer.MustRegister(volthaOnuEthFrameExtOversizeFrames)
+	registerer.MustRegister(volthaOnuEthFrameExt64Octet)
+	registerer.MustRegister(volthaOnuEthFrameExt65To127Octet)
+	registerer.MustRegister(volthaOnuEthFrameExt128To255Octet)
+	registerer.MustRegister(volthaOnuEthFrameExt256To511Octet)
+	registerer.MustRegister(volthaOnuEthFrameExt512To1023Octet)
+	registerer.MustRegister(volthaOnuEthFrameExt1024To1518Octet)
+
+	registerer.MustRegister(volthaOnuGemPortFrames)
+	registerer.MustRegister(volthaOnuGemPortPayloadBytes)
+	registerer.MustRegister(volthaOnuGemPortEncryptionKeyErrors)
+
+	registerer.MustRegister(volthaOnuBridgePortRxBytesTotal)
+	registerer.MustRegister(volthaOnuBridgePortRxPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePort_64octetRxPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePort_65_127_octetRxPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePort_128_255_octetRxPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePort_256_511_octetRxPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePort_512_1023_octetRxPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePort_1024_1518_octetRxPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePortRxMulticastPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePortRxBroadcastPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePortRxOversizePacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePortRxCrcErrorPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePortRxUndersizePacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePortRxDropEventsTotal)
+
+	registerer.MustRegister(volthaOnuBridgePortTxBytesTotal)
+	registerer.MustRegister(volthaOnuBridgePortTxPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePort_64octetTxPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePort_65_127_octetTxPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePort_128_255_octetTxPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePort_256_511_octetTxPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePort_512_1023_octetTxPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePort_1024_1518_octetTxPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePortTxMulticastPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePortTxBroadcastPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePortTxOversizePacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePortTxCrcErrorPacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePortTxUndersizePacketsTotal)
+	registerer.MustRegister(volthaOnuBridgePortTxDropEventsTotal)
+
+	registerer.MustRegister(onosTxBytesTotal)
+	registerer.MustRegister(onosRxBytesTotal)
+	registerer.MustRegister(onosTxPacketsTotal)
+	registerer.MustRegister(onosRxPacketsTotal)
+	registerer.MustRegister(onosTxDropPacketsTotal)
+	registerer.MustRegister(onosRxDropPacketsTotal)
+	registerer.MustRegister(onosPortInfo)
+	registerer.MustRegister(onosPortEnabled)
+
+	registerer.MustRegister(onosaaaRxAcceptResponses)
+	registerer.MustRegister(onosaaaRxRejectResponses)
+	registerer.MustRegister(onosaaaRxChallengeResponses)
+	registerer.MustRegister(onosaaaTxAccessRequests)
+	registerer.MustRegister(onosaaaRxInvalidValidators)
+	registerer.MustRegister(onosaaaRxUnknownType)
+	registerer.MustRegister(onosaaaPendingRequests)
+	registerer.MustRegister(onosaaaRxDroppedResponses)
+	registerer.MustRegister(onosaaaRxMalformedResponses)
+	registerer.MustRegister(onosaaaRxUnknownserver)
+	registerer.MustRegister(onosaaaRequestRttMillis)
+	registerer.MustRegister(onosaaaRequestReTx)
+
+	registerer.MustRegister(onosBngUpTxBytesTotal)
+	registerer.MustRegister(onosBngUpTxPacketsTotal)
+	registerer.MustRegister(onosBngUpRxBytesTotal)
+	registerer.MustRegister(onosBngUpRxPacketsTotal)
+	registerer.MustRegister(onosBngUpDropBytesTotal)
+	registerer.MustRegister(onosBngUpDropPacketsTotal)
+	registerer.MustRegister(onosBngControlPacketsTotal)
+	registerer.MustRegister(onosBngDownTxBytesTotal)
+	registerer.MustRegister(onosBngDownTxPacketsTotal)
+	registerer.MustRegister(onosBngDownRxBytesTotal)
+	registerer.MustRegister(onosBngDownRxPacketsTotal)
+	registerer.MustRegister(onosBngDownDropBytesTotal)
+	registerer.MustRegister(onosBngDownDropPacketsTotal)
+	registerer.MustRegister(onosBngSessionInfo)
+	registerer.MustRegister(onosBngSessionStartTime)
+	registerer.MustRegister(onosBngActiveSessions)
+	registerer.MustRegister(onosBngActiveSessionsPerSTag)
+
+	registerer.MustRegister(deviceLaserBiasCurrent)
+	registerer.MustRegister(deviceTemperature)
+	registerer.MustRegister(deviceTxPower)
+	registerer.MustRegister(deviceVoltage)
+
+	registerer.MustRegister(onosaaaRxEapolLogoff)
+	registerer.MustRegister(onosaaaTxEapolResIdentityMsg)
+	registerer.MustRegister(onosaaaTxAuthSuccess)
+	registerer.MustRegister(onosaaaTxAuthFailure)
+	registerer.MustRegister(onosaaaTxStartReq)
+	registerer.MustRegister(onosaaaEapPktTxAuthChooseEap)
+	registerer.MustRegister(onosaaaTxRespnotNak)
+
+	registerer.MustRegister(onosaaaEapolFramesTx)
+	registerer.MustRegister(onosaaaAuthStateIdle)
+	registerer.MustRegister(onosaaaRequestIdFramesTx)
+	registerer.MustRegister(onosaaaRequestEapFramesTx)
+	registerer.MustRegister(onosaaaInvalidPktType)
+	registerer.MustRegister(onosaaaInvalidBodyLength)
+	registerer.MustRegister(onosaaaValidEapolFramesRx)
+	registerer.MustRegister(onosaaaPendingResSupplicant)
+	registerer.MustRegister(onosaaaRxResIdEapFrames)
+
+	registerer.MustRegister(onosDhcpDiscoverTotal)
+	registerer.MustRegister(onosDhcpOfferTotal)
+	registerer.MustRegister(onosDhcpRequestTotal)
+	registerer.MustRegister(onosDhcpAckTotal)
+	registerer.MustRegister(onosDhcpNakTotal)
+	registerer.MustRegister(onosDhcpDeclineTotal)
+	registerer.MustRegister(onosDhcpReleaseTotal)
+	registerer.MustRegister(onosDhcpInformTotal)
+
+	registerer.MustRegister(onosIgmpJoinTotal)
+	registerer.MustRegister(onosIgmpLeaveTotal)
+	registerer.MustRegister(onosIgmpGeneralQueryTotal)
+	registerer.MustRegister(onosIgmpGroupSpecificQueryTotal)
+	registerer.MustRegister(onosIgmpMembershipReportTotal)
+	registerer.MustRegister(onosIgmpInvalidPacketsTotal)
+
+	registerer.MustRegister(onosMcastActiveGroups)
+	registerer.MustRegister(onosMcastSinks)
+
+	registerer.MustRegister(onosAaaSubscriberState)
+	registerer.MustRegister(onosAaaSubscriberStateTimestamp)
+	registerer.MustRegister(onosAaaSubscriberStateTransitions)
 
 	//device metrics
 	//TODO: Check if component level temperatures are supported by Devices,If not remove in later versions of exporter
-	prometheus.MustRegister(oltDeviceCpuTemp)
-	prometheus.MustRegister(oltDeviceCpuUsagePercent)
-	prometheus.MustRegister(oltDeviceFanSpeed)
-	prometheus.MustRegister(oltDeviceDiskTemp)
-	prometheus.MustRegister(oltDeviceDiskUsagePercent)
-	prometheus.MustRegister(oltDeviceRamTemp)
-	prometheus.MustRegister(oltDeviceRamUsagePercent)
-	prometheus.MustRegister(oltDevicePowerUsagePercent)
-	prometheus.MustRegister(oltDeviceInnerSurroundTemp)
-	prometheus.MustRegister(oltDevicePowerUsage)
+	registerer.MustRegister(oltDeviceCpuTemp)
+	registerer.MustRegister(oltDeviceCpuUsagePercent)
+	registerer.MustRegister(oltDeviceFanSpeed)
+	registerer.MustRegister(oltDeviceDiskTemp)
+	registerer.MustRegister(oltDeviceDiskUsagePercent)
+	registerer.MustRegister(oltDeviceRamTemp)
+	registerer.MustRegister(oltDeviceRamUsagePercent)
+	registerer.MustRegister(oltDevicePowerUsagePercent)
+	registerer.MustRegister(oltDeviceInnerSurroundTemp)
+	registerer.MustRegister(oltDevicePowerUsage)
 }
 
-func loadConfigFile() Config {
+func loadConfigFile(path string) Config {
 	m := Config{}
-	// this file path is configmap mounted in pod yaml
-	yamlFile, err := ioutil.ReadFile("/etc/config/conf.yaml")
+	yamlFile, err := ioutil.ReadFile(path)
 	if err != nil {
 		log.Printf("yamlFile.Get err: %v ", err)
 	}
@@ -290,16 +379,152 @@ func loadConfigFile() Config {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "replay":
+			os.Exit(replayCommand(os.Args[2:]))
+		case "record":
+			os.Exit(recordCommand(os.Args[2:]))
+		}
+	}
+
 	// load configuration
-	conf := loadConfigFile()
+	conf := loadConfigFile(cDefaultConfigFile)
 
 	// logger setup
 	logger.Setup(conf.Logger.Host, strings.ToUpper(conf.Logger.LogLevel))
 	logger.Info("Connecting to broker: [%s]", conf.Broker.Host)
+
+	if err := setupPipeline(conf); err != nil {
+		logger.Fatal("Invalid configuration: %s", err.Error())
+	}
+	startOutputs(conf)
+
+	go expireBngSessions()
+	go kafkaInit(conf.Broker)
+	runServer(conf.Target)
+}
+
+// setupPipeline registers the metrics and builds what /metrics serves
+// from the configuration, for the live topics as for a replay
+func setupPipeline(conf Config) error {
 	utils.OnuSNhex = conf.Conv.Onusnhex
 	logger.Info("The utils.OnuSNhex : [%t]", utils.OnuSNhex)
 	logger.Info("The conf.Conv.Onusnformat is : [%t]", conf.Conv.Onusnhex)
+	setVolthaPassthrough(conf.Voltha.Passthrough)
+	logger.Info("The KpiEvent2 passthrough titles are : %v", conf.Voltha.Passthrough)
 
-	go kafkaInit(conf.Broker)
-	runServer(conf.Target)
+	relabelRules, err := compileRelabelRules(conf.Relabel)
+	if err != nil {
+		return fmt.Errorf("invalid relabel rules: %s", err.Error())
+	}
+	if len(relabelRules) > 0 {
+		metricsRegisterer = &relabelRegisterer{Registerer: metricsRegisterer, rules: relabelRules}
+		logger.Info("Applying [%d] relabel rules", len(relabelRules))
+	}
+	registerMetrics(metricsRegisterer)
+	if conf.Target.GoCollector {
+		metricsRegisterer.MustRegister(prometheus.NewGoCollector())
+	}
+	if conf.Target.ProcessCollector {
+		metricsRegisterer.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+	}
+
+	if conf.Aggregation.Enabled {
+		volthaAggregates = newVolthaAggregator(conf.Aggregation)
+		metricsRegisterer.MustRegister(volthaAggregates)
+		logger.Info("ONU aggregates enabled per OLT [%t] and per PON [%t]", conf.Aggregation.PerOlt, conf.Aggregation.PerPon)
+	}
+
+	if conf.Inventory.Enabled {
+		inventory = newDeviceInventory(conf.Inventory)
+		metricsRegisterer.MustRegister(inventory)
+		logger.Info("Device inventory enabled with extra labels %s", inventory.extraLabels)
+	}
+
+	if conf.Sadis.Enabled {
+		sadisSubscribers = newSadisSubscriberCache()
+		gathererWrappers = append(gathererWrappers, func(g prometheus.Gatherer) prometheus.Gatherer {
+			return &subscriberGatherer{Gatherer: g}
+		})
+		go refreshSadisSubscribers(conf.Sadis)
+		logger.Info("SADIS subscribers loaded from [%s] every [%s]", conf.Sadis.Url, conf.Sadis.Refresh)
+	}
+
+	// relabelling last, so that the rules also apply to the joined labels
+	if len(relabelRules) > 0 {
+		gathererWrappers = append(gathererWrappers, func(g prometheus.Gatherer) prometheus.Gatherer {
+			return &relabelGatherer{Gatherer: g, rules: relabelRules}
+		})
+	}
+	if conf.Target.Namespace != "" || len(conf.Target.ConstLabels) > 0 {
+		namespaced, err := newNamespaceGatherer(metricsRegistry, conf.Target)
+		if err != nil {
+			return fmt.Errorf("invalid target namespace or const labels: %s", err.Error())
+		}
+		gathererWrappers = append(gathererWrappers, namespaced.wrap)
+		logger.Info("Exporting with namespace [%s] and const labels %v", conf.Target.Namespace, conf.Target.ConstLabels)
+	}
+	metricsGatherer = wrapGatherer(metricsRegistry)
+	return nil
+}
+
+// startOutputs starts the configured outputs besides /metrics
+func startOutputs(conf Config) {
+	if conf.Target.RemoteWrite.Enabled {
+		metricsRegisterer.MustRegister(remoteWriteSentSamples, remoteWriteFailedSamples, remoteWriteDroppedSamples, remoteWriteQueueLength)
+		go newRemoteWriter(conf.Target.RemoteWrite, metricsGatherer).run()
+		logger.Info("Pushing the metrics to the remote-write endpoint [%s]", conf.Target.RemoteWrite.Url)
+	}
+
+	// the outputs carrying timestamps send the time the devices reported
+	// the values at
+	if conf.Target.Otlp.Enabled || conf.Target.Influx.Enabled || conf.Target.KafkaOutput.Enabled {
+		sourceTimestamps = newSourceTimestampCache()
+		go sourceTimestamps.run()
+	}
+
+	if conf.Target.Otlp.Enabled {
+		metricSinks = append(metricSinks, sourceTimestamps)
+		exporter, err := newOtlpExporter(conf.Target.Otlp, wrapGatherer(&sourceTimestampGatherer{Gatherer: metricsRegistry, cache: sourceTimestamps}))
+		if err != nil {
+			logger.Fatal("Invalid OTLP configuration: %s", err.Error())
+		}
+		go exporter.run()
+		logger.Info("Exporting the metrics over OTLP/%s to [%s]", exporter.conf.Protocol, conf.Target.Otlp.Endpoint)
+	}
+
+	if conf.Target.Influx.Enabled {
+		writer, err := newInfluxWriter(conf.Target.Influx)
+		if err != nil {
+			logger.Fatal("Invalid InfluxDB configuration: %s", err.Error())
+		}
+		metricsRegisterer.MustRegister(influxDroppedLines)
+		metricSinks = append(metricSinks, pipelineSink{writer})
+		go writer.run()
+		logger.Info("Writing the metrics to InfluxDB [%s%s]", conf.Target.Influx.Url, conf.Target.Influx.File)
+	}
+
+	if conf.Target.KafkaOutput.Enabled {
+		publisher, err := newKafkaPublisher(conf.Target.KafkaOutput, conf.Broker)
+		if err != nil {
+			logger.Fatal("Invalid Kafka output configuration: %s", err.Error())
+		}
+		metricsRegisterer.MustRegister(kafkaOutputPublished, kafkaOutputFailed, kafkaOutputDropped)
+		metricSinks = append(metricSinks, pipelineSink{publisher})
+		go publisher.run()
+		logger.Info("Publishing the normalized metrics as %s to [%s]", publisher.conf.Format, conf.Target.KafkaOutput.Topic)
+	}
+
+	for kind, pushConf := range map[string]LegacyPushInfo{graphitePush: conf.Target.Graphite, statsdPush: conf.Target.Statsd} {
+		if !pushConf.Enabled {
+			continue
+		}
+		pusher, err := newLegacyPusher(kind, pushConf, metricsGatherer)
+		if err != nil {
+			logger.Fatal("Invalid %s configuration: %s", kind, err.Error())
+		}
+		go pusher.run()
+		logger.Info("Pushing the metrics to %s [%s] every [%s]", kind, pusher.conf.Address, pusher.conf.Interval)
+	}
 }
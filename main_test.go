@@ -0,0 +1,59 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsHandlerOpenMetrics(t *testing.T) {
+	defer func(gatherer prometheus.Gatherer) { metricsGatherer = gatherer }(metricsGatherer)
+	metricsGatherer = newTestRegistry()
+	exportOnosKPI(OnosKPI{
+		DeviceID: "of:0000000000000001",
+		Ports:    []*OnosPort{{PortID: "16", TxBytes: 1024}},
+	})
+	server := httptest.NewServer(metricsHandler())
+	defer server.Close()
+
+	scrape := func(accept string) (string, string) {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Accept", accept)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return resp.Header.Get("Content-Type"), string(body)
+	}
+
+	contentType, body := scrape("application/openmetrics-text; version=0.0.1,text/plain;version=0.0.4;q=0.5,*/*;q=0.1")
+	assert.True(t, strings.HasPrefix(contentType, "application/openmetrics-text; version=0.0.1"), contentType)
+	assert.Contains(t, body, `onos_tx_bytes_total{device_id="of:0000000000000001",port_id="16"} 1024`)
+	assert.True(t, strings.HasSuffix(body, "# EOF\n"))
+
+	// the scrapers not asking for OpenMetrics still get the text format
+	contentType, body = scrape("text/plain")
+	assert.True(t, strings.HasPrefix(contentType, "text/plain; version=0.0.4"), contentType)
+	assert.NotContains(t, body, "# EOF")
+}
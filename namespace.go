@@ -0,0 +1,98 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var (
+	namespaceRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// namespaceGatherer prefixes every family with the deployment namespace
+// and adds the deployment const labels to every series, the self-metrics
+// of the exporter included
+type namespaceGatherer struct {
+	prometheus.Gatherer
+	prefix      string
+	constLabels []*dto.LabelPair
+}
+
+func newNamespaceGatherer(gatherer prometheus.Gatherer, target TargetInfo) (*namespaceGatherer, error) {
+	g := &namespaceGatherer{Gatherer: gatherer}
+	if target.Namespace != "" {
+		if !namespaceRegexp.MatchString(target.Namespace) {
+			return nil, fmt.Errorf("invalid namespace [%s]", target.Namespace)
+		}
+		g.prefix = target.Namespace + "_"
+	}
+	for name, value := range target.ConstLabels {
+		if !labelNameRegexp.MatchString(name) || strings.HasPrefix(name, "__") {
+			return nil, fmt.Errorf("invalid const label name [%s]", name)
+		}
+		g.constLabels = append(g.constLabels, labelPair(name, value))
+	}
+	sort.Slice(g.constLabels, func(i, j int) bool {
+		return g.constLabels[i].GetName() < g.constLabels[j].GetName()
+	})
+	return g, nil
+}
+
+// wrap returns a copy of the gatherer applied to another one
+func (g *namespaceGatherer) wrap(gatherer prometheus.Gatherer) prometheus.Gatherer {
+	wrapped := *g
+	wrapped.Gatherer = gatherer
+	return &wrapped
+}
+
+// Gather implements prometheus.Gatherer
+func (g *namespaceGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	for _, mf := range mfs {
+		name := g.prefix + mf.GetName()
+		mf.Name = &name
+		for _, m := range mf.GetMetric() {
+			for _, constLabel := range g.constLabels {
+				if hasLabel(m.Label, constLabel.GetName()) {
+					logger.Debug("Not adding const label [%s] to [%s], the series already has it", constLabel.GetName(), name)
+					continue
+				}
+				m.Label = append(m.Label, constLabel)
+			}
+			sort.Slice(m.Label, func(i, j int) bool {
+				return m.Label[i].GetName() < m.Label[j].GetName()
+			})
+		}
+	}
+	return mfs, err
+}
+
+func hasLabel(labels []*dto.LabelPair, name string) bool {
+	for _, pair := range labels {
+		if pair.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
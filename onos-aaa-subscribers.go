@@ -0,0 +1,90 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sync"
+)
+
+// authentication states reported by the ONOS AAA app for a supplicant
+var onosAaaAuthStates = []string{"STARTED", "REQUESTED", "APPROVED", "DENIED", "TIMEOUT", "LOGOFF"}
+
+func isOnosAaaAuthState(state string) bool {
+	for _, s := range onosAaaAuthStates {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+type onosAaaSubscriber struct {
+	DeviceID   string
+	PortNumber string
+	OnuSerial  string
+	Mac        string
+	State      string
+}
+
+// onosAaaSubscriberCache keeps the current authentication state per
+// device, port and supplicant MAC address
+type onosAaaSubscriberCache struct {
+	sync.Mutex
+	subscribers map[string]*onosAaaSubscriber
+}
+
+var onosAaaSubscribers = &onosAaaSubscriberCache{
+	subscribers: make(map[string]*onosAaaSubscriber),
+}
+
+func (s *onosAaaSubscriber) key() string {
+	return s.DeviceID + "/" + s.PortNumber + "/" + s.Mac
+}
+
+// update stores the subscriber and returns its previous state, if any
+func (c *onosAaaSubscriberCache) update(sub *onosAaaSubscriber) *onosAaaSubscriber {
+	c.Lock()
+	defer c.Unlock()
+
+	old := c.subscribers[sub.key()]
+	c.subscribers[sub.key()] = sub
+	return old
+}
+
+// remove forgets the subscriber and returns it, if it was known
+func (c *onosAaaSubscriberCache) remove(sub *onosAaaSubscriber) *onosAaaSubscriber {
+	c.Lock()
+	defer c.Unlock()
+
+	old := c.subscribers[sub.key()]
+	delete(c.subscribers, sub.key())
+	return old
+}
+
+// removePort forgets and returns the subscribers of a device port, or of
+// every port of the device when portNumber is empty
+func (c *onosAaaSubscriberCache) removePort(deviceID string, portNumber string) []*onosAaaSubscriber {
+	c.Lock()
+	defer c.Unlock()
+
+	var removed []*onosAaaSubscriber
+	for key, sub := range c.subscribers {
+		if sub.DeviceID == deviceID && (portNumber == "" || sub.PortNumber == portNumber) {
+			removed = append(removed, sub)
+			delete(c.subscribers, key)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"sync"
+)
+
+// OnosPortInfo holds the port annotations learned from onos.events
+type OnosPortInfo struct {
+	Name      string
+	Type      string
+	Speed     string
+	Enabled   bool
+	OnuSerial string
+}
+
+// onosPortCache keeps the last known OnosPortInfo per device and port
+type onosPortCache struct {
+	sync.RWMutex
+	ports map[string]map[string]*OnosPortInfo
+}
+
+var onosPorts = &onosPortCache{
+	ports: make(map[string]map[string]*OnosPortInfo),
+}
+
+// update stores the port info and returns the previous one, if any
+func (c *onosPortCache) update(deviceID string, portID string, info *OnosPortInfo) *OnosPortInfo {
+	c.Lock()
+	defer c.Unlock()
+
+	devicePorts, ok := c.ports[deviceID]
+	if !ok {
+		devicePorts = make(map[string]*OnosPortInfo)
+		c.ports[deviceID] = devicePorts
+	}
+	old := devicePorts[portID]
+	devicePorts[portID] = info
+	return old
+}
+
+// remove drops a single port and returns its last known info, if any
+func (c *onosPortCache) remove(deviceID string, portID string) *OnosPortInfo {
+	c.Lock()
+	defer c.Unlock()
+
+	devicePorts, ok := c.ports[deviceID]
+	if !ok {
+		return nil
+	}
+	old := devicePorts[portID]
+	delete(devicePorts, portID)
+	if len(devicePorts) == 0 {
+		delete(c.ports, deviceID)
+	}
+	return old
+}
+
+// removeDevice drops all the ports of a device and returns them
+func (c *onosPortCache) removeDevice(deviceID string) map[string]*OnosPortInfo {
+	c.Lock()
+	defer c.Unlock()
+
+	devicePorts := c.ports[deviceID]
+	delete(c.ports, deviceID)
+	return devicePorts
+}
+
+func (c *onosPortCache) get(deviceID string, portID string) (*OnosPortInfo, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	info, ok := c.ports[deviceID][portID]
+	return info, ok
+}
+
+// find returns the port of an ONU by its serial number and port number,
+// whatever the ONOS device it belongs to
+func (c *onosPortCache) find(onuSerial string, portID string) (*OnosPortInfo, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	for _, devicePorts := range c.ports {
+		if info, ok := devicePorts[portID]; ok && info.OnuSerial == onuSerial {
+			return info, true
+		}
+	}
+	return nil, false
+}
+
+// onuSerialFromPortName extracts the ONU serial number from the name ONOS
+// gives to UNI ports, e.g. 'BBSM00000001-1'. An empty string is returned
+// for ports that are not named after an ONU (NNI ports for example).
+func onuSerialFromPortName(portName string) string {
+	idx := strings.LastIndex(portName, "-")
+	if idx != 12 {
+		return ""
+	}
+	return portName[:idx]
+}
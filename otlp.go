@@ -0,0 +1,391 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"time"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	otlpProtocolGrpc = "grpc"
+	otlpProtocolHttp = "http"
+
+	cDefaultOtlpInterval = 15 * time.Second
+	cDefaultOtlpTimeout  = 10 * time.Second
+
+	otlpExportMethod = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+	otlpScopeName    = "kafka-topic-exporter"
+
+	// AggregationTemporality CUMULATIVE
+	otlpCumulative = 2
+)
+
+// labels that identify the VOLTHA or DMI device, exported as resource
+// attributes rather than data point attributes
+var cDefaultOtlpResourceLabels = []string{"logical_device_id", "device_id", "serial_number", "deviceuuid"}
+
+// otlpExporter periodically exports the gathered series to an
+// OpenTelemetry collector, over OTLP/gRPC or OTLP/HTTP
+type otlpExporter struct {
+	conf           OtlpInfo
+	gatherer       prometheus.Gatherer
+	resourceLabels map[string]bool
+	// start of the cumulative sums, histograms and summaries
+	start time.Time
+	conn  *grpc.ClientConn
+	http  *http.Client
+}
+
+func newOtlpExporter(conf OtlpInfo, gatherer prometheus.Gatherer) (*otlpExporter, error) {
+	if conf.Protocol == "" {
+		conf.Protocol = otlpProtocolGrpc
+	}
+	if conf.Interval == 0 {
+		conf.Interval = cDefaultOtlpInterval
+	}
+	if conf.Timeout == 0 {
+		conf.Timeout = cDefaultOtlpTimeout
+	}
+	if len(conf.ResourceLabels) == 0 {
+		conf.ResourceLabels = cDefaultOtlpResourceLabels
+	}
+
+	e := &otlpExporter{
+		conf:           conf,
+		gatherer:       gatherer,
+		resourceLabels: make(map[string]bool),
+		start:          time.Now(),
+	}
+	for _, label := range conf.ResourceLabels {
+		e.resourceLabels[label] = true
+	}
+
+	switch conf.Protocol {
+	case otlpProtocolGrpc:
+		creds := credentials.NewTLS(&tls.Config{})
+		if conf.Insecure {
+			creds = insecure.NewCredentials()
+		}
+		conn, err := grpc.Dial(conf.Endpoint, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, err
+		}
+		e.conn = conn
+	case otlpProtocolHttp:
+		e.http = &http.Client{Timeout: conf.Timeout}
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol [%s]", conf.Protocol)
+	}
+	return e, nil
+}
+
+func (e *otlpExporter) run() {
+	ticker := time.NewTicker(e.conf.Interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := e.export(); err != nil {
+			logger.Error("Cannot export the metrics to the OTLP endpoint [%s]: %s", e.conf.Endpoint, err.Error())
+		}
+	}
+}
+
+func (e *otlpExporter) export() error {
+	mfs, err := e.gatherer.Gather()
+	if err != nil {
+		logger.Warn("Errors gathering the metrics for OTLP: %s", err.Error())
+	}
+	request := encodeOtlpMetrics(mfs, e.resourceLabels, e.start, time.Now())
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.conf.Timeout)
+	defer cancel()
+	if e.conn != nil {
+		return e.exportGrpc(ctx, request)
+	}
+	return e.exportHttp(ctx, request)
+}
+
+func (e *otlpExporter) exportGrpc(ctx context.Context, request []byte) error {
+	for name, value := range e.conf.Headers {
+		ctx = metadata.AppendToOutgoingContext(ctx, name, value)
+	}
+	var response []byte
+	return e.conn.Invoke(ctx, otlpExportMethod, &request, &response, grpc.ForceCodec(otlpRawCodec{}))
+}
+
+func (e *otlpExporter) exportHttp(ctx context.Context, request []byte) error {
+	req, err := http.NewRequest(http.MethodPost, e.conf.Endpoint, bytes.NewReader(request))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "kafka-topic-exporter")
+	for name, value := range e.conf.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := e.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("server returned %s: %s", resp.Status, bytes.TrimSpace(message))
+	}
+	return nil
+}
+
+// otlpRawCodec sends the requests already encoded as protobuf, as there
+// are no generated OTLP types to marshal
+type otlpRawCodec struct{}
+
+func (otlpRawCodec) Marshal(v interface{}) ([]byte, error) {
+	return *v.(*[]byte), nil
+}
+
+func (otlpRawCodec) Unmarshal(data []byte, v interface{}) error {
+	*v.(*[]byte) = data
+	return nil
+}
+
+func (otlpRawCodec) Name() string {
+	return "proto"
+}
+
+// otlpResource holds the encoded metrics of one device
+type otlpResource struct {
+	attributes []*dto.LabelPair
+	metrics    []*otlpMetric
+	byName     map[string]*otlpMetric
+}
+
+type otlpMetric struct {
+	family *dto.MetricFamily
+	points [][]byte
+}
+
+// encodeOtlpMetrics encodes the families as an OTLP
+// ExportMetricsServiceRequest, with one ResourceMetrics per device. The
+// counters become cumulative monotonic sums, the gauges and untyped
+// metrics gauges.
+func encodeOtlpMetrics(mfs []*dto.MetricFamily, resourceLabels map[string]bool, start time.Time, now time.Time) []byte {
+	var resources []*otlpResource
+	byKey := make(map[string]*otlpResource)
+
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			var resourceAttrs, pointAttrs []*dto.LabelPair
+			for _, pair := range m.GetLabel() {
+				if resourceLabels[pair.GetName()] {
+					resourceAttrs = append(resourceAttrs, pair)
+				} else {
+					pointAttrs = append(pointAttrs, pair)
+				}
+			}
+
+			key := labelsSignature(resourceAttrs)
+			resource, ok := byKey[key]
+			if !ok {
+				resource = &otlpResource{attributes: resourceAttrs, byName: make(map[string]*otlpMetric)}
+				byKey[key] = resource
+				resources = append(resources, resource)
+			}
+			metric, ok := resource.byName[mf.GetName()]
+			if !ok {
+				metric = &otlpMetric{family: mf}
+				resource.byName[mf.GetName()] = metric
+				resource.metrics = append(resource.metrics, metric)
+			}
+
+			// the source timestamp if known, see sourceTimestampGatherer
+			ts := now
+			if m.TimestampMs != nil {
+				ts = time.Unix(0, m.GetTimestampMs()*int64(time.Millisecond))
+			}
+			metric.points = append(metric.points, encodeOtlpPoint(mf.GetType(), m, pointAttrs, start, ts))
+		}
+	}
+
+	var request []byte
+	for _, resource := range resources {
+		var resourceMetrics []byte
+
+		var res []byte
+		res = appendOtlpAttribute(res, 1, "service.name", otlpScopeName)
+		for _, pair := range resource.attributes {
+			res = appendOtlpAttribute(res, 1, pair.GetName(), pair.GetValue())
+		}
+		resourceMetrics = appendOtlpMessage(resourceMetrics, 1, res)
+
+		var scopeMetrics []byte
+		var scope []byte
+		scope = protowire.AppendTag(scope, 1, protowire.BytesType)
+		scope = protowire.AppendString(scope, otlpScopeName)
+		scopeMetrics = appendOtlpMessage(scopeMetrics, 1, scope)
+		for _, metric := range resource.metrics {
+			scopeMetrics = appendOtlpMessage(scopeMetrics, 2, encodeOtlpMetric(metric))
+		}
+		resourceMetrics = appendOtlpMessage(resourceMetrics, 2, scopeMetrics)
+
+		request = appendOtlpMessage(request, 1, resourceMetrics)
+	}
+	return request
+}
+
+// encodeOtlpMetric encodes a Metric, the data field depending on the type
+//
+//	Metric    { string name = 1; string description = 2; Gauge gauge = 5;
+//	            Sum sum = 7; Histogram histogram = 9; Summary summary = 11; }
+//	Gauge     { repeated NumberDataPoint data_points = 1; }
+//	Sum       { repeated NumberDataPoint data_points = 1;
+//	            AggregationTemporality aggregation_temporality = 2; bool is_monotonic = 3; }
+//	Histogram { repeated HistogramDataPoint data_points = 1;
+//	            AggregationTemporality aggregation_temporality = 2; }
+//	Summary   { repeated SummaryDataPoint data_points = 1; }
+func encodeOtlpMetric(metric *otlpMetric) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, metric.family.GetName())
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendString(b, metric.family.GetHelp())
+
+	var data []byte
+	for _, point := range metric.points {
+		data = appendOtlpMessage(data, 1, point)
+	}
+	switch metric.family.GetType() {
+	case dto.MetricType_COUNTER:
+		data = protowire.AppendTag(data, 2, protowire.VarintType)
+		data = protowire.AppendVarint(data, otlpCumulative)
+		data = protowire.AppendTag(data, 3, protowire.VarintType)
+		data = protowire.AppendVarint(data, 1)
+		b = appendOtlpMessage(b, 7, data)
+	case dto.MetricType_HISTOGRAM:
+		data = protowire.AppendTag(data, 2, protowire.VarintType)
+		data = protowire.AppendVarint(data, otlpCumulative)
+		b = appendOtlpMessage(b, 9, data)
+	case dto.MetricType_SUMMARY:
+		b = appendOtlpMessage(b, 11, data)
+	default:
+		b = appendOtlpMessage(b, 5, data)
+	}
+	return b
+}
+
+// encodeOtlpPoint encodes a data point
+//
+//	NumberDataPoint    { fixed64 start_time_unix_nano = 2; fixed64 time_unix_nano = 3;
+//	                     double as_double = 4; repeated KeyValue attributes = 7; }
+//	HistogramDataPoint { fixed64 start_time_unix_nano = 2; fixed64 time_unix_nano = 3;
+//	                     fixed64 count = 4; double sum = 5; repeated fixed64 bucket_counts = 6;
+//	                     repeated double explicit_bounds = 7; repeated KeyValue attributes = 9; }
+//	SummaryDataPoint   { fixed64 start_time_unix_nano = 2; fixed64 time_unix_nano = 3;
+//	                     fixed64 count = 4; double sum = 5;
+//	                     repeated ValueAtQuantile quantile_values = 6; repeated KeyValue attributes = 7; }
+func encodeOtlpPoint(metricType dto.MetricType, m *dto.Metric, attributes []*dto.LabelPair, start time.Time, ts time.Time) []byte {
+	var b []byte
+	if metricType != dto.MetricType_GAUGE && metricType != dto.MetricType_UNTYPED {
+		b = appendOtlpFixed64(b, 2, uint64(start.UnixNano()))
+	}
+	b = appendOtlpFixed64(b, 3, uint64(ts.UnixNano()))
+
+	attributesField := protowire.Number(7)
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		b = appendOtlpFixed64(b, 4, math.Float64bits(m.GetCounter().GetValue()))
+	case dto.MetricType_GAUGE:
+		b = appendOtlpFixed64(b, 4, math.Float64bits(m.GetGauge().GetValue()))
+	case dto.MetricType_UNTYPED:
+		b = appendOtlpFixed64(b, 4, math.Float64bits(m.GetUntyped().GetValue()))
+	case dto.MetricType_HISTOGRAM:
+		h := m.GetHistogram()
+		b = appendOtlpFixed64(b, 4, h.GetSampleCount())
+		b = appendOtlpFixed64(b, 5, math.Float64bits(h.GetSampleSum()))
+		// OTLP buckets are not cumulative and end with the +Inf one
+		var counts, bounds []byte
+		previous := uint64(0)
+		for _, bucket := range h.GetBucket() {
+			if math.IsInf(bucket.GetUpperBound(), +1) {
+				continue
+			}
+			counts = protowire.AppendFixed64(counts, bucket.GetCumulativeCount()-previous)
+			bounds = protowire.AppendFixed64(bounds, math.Float64bits(bucket.GetUpperBound()))
+			previous = bucket.GetCumulativeCount()
+		}
+		counts = protowire.AppendFixed64(counts, h.GetSampleCount()-previous)
+		b = appendOtlpMessage(b, 6, counts)
+		b = appendOtlpMessage(b, 7, bounds)
+		attributesField = 9
+	case dto.MetricType_SUMMARY:
+		s := m.GetSummary()
+		b = appendOtlpFixed64(b, 4, s.GetSampleCount())
+		b = appendOtlpFixed64(b, 5, math.Float64bits(s.GetSampleSum()))
+		for _, q := range s.GetQuantile() {
+			var quantile []byte
+			quantile = appendOtlpFixed64(quantile, 1, math.Float64bits(q.GetQuantile()))
+			quantile = appendOtlpFixed64(quantile, 2, math.Float64bits(q.GetValue()))
+			b = appendOtlpMessage(b, 6, quantile)
+		}
+	}
+
+	for _, pair := range attributes {
+		b = appendOtlpAttribute(b, attributesField, pair.GetName(), pair.GetValue())
+	}
+	return b
+}
+
+func appendOtlpMessage(b []byte, num protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+func appendOtlpFixed64(b []byte, num protowire.Number, value uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, value)
+}
+
+// appendOtlpAttribute appends a KeyValue with a string value
+//
+//	KeyValue { string key = 1; AnyValue value = 2; }
+//	AnyValue { string string_value = 1; }
+func appendOtlpAttribute(b []byte, num protowire.Number, key string, value string) []byte {
+	var anyValue []byte
+	anyValue = protowire.AppendTag(anyValue, 1, protowire.BytesType)
+	anyValue = protowire.AppendString(anyValue, value)
+
+	var keyValue []byte
+	keyValue = protowire.AppendTag(keyValue, 1, protowire.BytesType)
+	keyValue = protowire.AppendString(keyValue, key)
+	keyValue = appendOtlpMessage(keyValue, 2, anyValue)
+	return appendOtlpMessage(b, num, keyValue)
+}
@@ -0,0 +1,315 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// otlpField is a decoded protobuf field, bytes for the messages and
+// strings, number for the varint and fixed64 values
+type otlpField struct {
+	bytes  []byte
+	number uint64
+}
+
+// decodeOtlpFields returns the fields of a message by number
+func decodeOtlpFields(t *testing.T, b []byte) map[protowire.Number][]otlpField {
+	fields := make(map[protowire.Number][]otlpField)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if !assert.True(t, n > 0, "invalid tag") {
+			return fields
+		}
+		b = b[n:]
+		var field otlpField
+		switch typ {
+		case protowire.BytesType:
+			field.bytes, n = protowire.ConsumeBytes(b)
+		case protowire.Fixed64Type:
+			field.number, n = protowire.ConsumeFixed64(b)
+		case protowire.VarintType:
+			field.number, n = protowire.ConsumeVarint(b)
+		default:
+			t.Fatalf("unexpected wire type %d", typ)
+		}
+		if !assert.True(t, n > 0, "invalid field %d", num) {
+			return fields
+		}
+		b = b[n:]
+		fields[num] = append(fields[num], field)
+	}
+	return fields
+}
+
+// decodeOtlpAttributes decodes repeated KeyValue with string values
+func decodeOtlpAttributes(t *testing.T, keyValues []otlpField) map[string]string {
+	attributes := make(map[string]string)
+	for _, keyValue := range keyValues {
+		fields := decodeOtlpFields(t, keyValue.bytes)
+		value := decodeOtlpFields(t, fields[2][0].bytes)
+		attributes[string(fields[1][0].bytes)] = string(value[1][0].bytes)
+	}
+	return attributes
+}
+
+type otlpTestPoint struct {
+	resource   map[string]string
+	attributes map[string]string
+	start      uint64
+	time       uint64
+	value      float64
+	monotonic  bool
+	// count, sum and non-cumulative bucket counts of the histograms
+	count   uint64
+	buckets []uint64
+	bounds  []float64
+}
+
+// decodeOtlpRequest flattens an ExportMetricsServiceRequest by metric name
+func decodeOtlpRequest(t *testing.T, request []byte) map[string][]otlpTestPoint {
+	points := make(map[string][]otlpTestPoint)
+	for _, resourceMetrics := range decodeOtlpFields(t, request)[1] {
+		fields := decodeOtlpFields(t, resourceMetrics.bytes)
+		resource := decodeOtlpAttributes(t, decodeOtlpFields(t, fields[1][0].bytes)[1])
+		for _, scopeMetrics := range fields[2] {
+			scope := decodeOtlpFields(t, scopeMetrics.bytes)
+			assert.Equal(t, otlpScopeName, string(decodeOtlpFields(t, scope[1][0].bytes)[1][0].bytes))
+			for _, metric := range scope[2] {
+				metricFields := decodeOtlpFields(t, metric.bytes)
+				name := string(metricFields[1][0].bytes)
+				for num, data := range metricFields {
+					if num != 5 && num != 7 && num != 9 {
+						continue
+					}
+					dataFields := decodeOtlpFields(t, data[0].bytes)
+					attributesField := protowire.Number(7)
+					if num == 9 {
+						attributesField = 9
+					}
+					for _, dataPoint := range dataFields[1] {
+						pointFields := decodeOtlpFields(t, dataPoint.bytes)
+						point := otlpTestPoint{
+							resource:   resource,
+							attributes: decodeOtlpAttributes(t, pointFields[attributesField]),
+							time:       pointFields[3][0].number,
+							monotonic:  len(dataFields[3]) > 0 && dataFields[3][0].number == 1,
+						}
+						if start, ok := pointFields[2]; ok {
+							point.start = start[0].number
+						}
+						if num == 9 {
+							point.count = pointFields[4][0].number
+							point.value = math.Float64frombits(pointFields[5][0].number)
+							for counts := pointFields[6][0].bytes; len(counts) > 0; counts = counts[8:] {
+								count, _ := protowire.ConsumeFixed64(counts)
+								point.buckets = append(point.buckets, count)
+							}
+							for bounds := pointFields[7][0].bytes; len(bounds) > 0; bounds = bounds[8:] {
+								bound, _ := protowire.ConsumeFixed64(bounds)
+								point.bounds = append(point.bounds, math.Float64frombits(bound))
+							}
+						} else {
+							point.value = math.Float64frombits(pointFields[4][0].number)
+						}
+						points[name] = append(points[name], point)
+					}
+				}
+			}
+		}
+	}
+	return points
+}
+
+func TestEncodeOtlpMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_power", Help: "test"}, []string{"device_id", "port"})
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_bytes_total", Help: "test"}, []string{"device_id"})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_rtt", Help: "test", Buckets: []float64{1, 10}})
+	registry.MustRegister(gauge, counter, histogram)
+	gauge.WithLabelValues("onu-1", "16").Set(-18.5)
+	counter.WithLabelValues("onu-1").Add(1024)
+	histogram.Observe(0.5)
+	histogram.Observe(5)
+	histogram.Observe(50)
+
+	mfs, err := registry.Gather()
+	assert.NoError(t, err)
+	start := time.Unix(1636106000, 0)
+	now := time.Unix(1636106400, 0)
+	points := decodeOtlpRequest(t, encodeOtlpMetrics(mfs, map[string]bool{"device_id": true}, start, now))
+
+	if assert.Len(t, points["test_power"], 1) {
+		point := points["test_power"][0]
+		assert.Equal(t, map[string]string{"service.name": otlpScopeName, "device_id": "onu-1"}, point.resource)
+		assert.Equal(t, map[string]string{"port": "16"}, point.attributes)
+		assert.Equal(t, -18.5, point.value)
+		assert.Equal(t, uint64(now.UnixNano()), point.time)
+		// gauges have no start time
+		assert.Zero(t, point.start)
+	}
+	if assert.Len(t, points["test_bytes_total"], 1) {
+		point := points["test_bytes_total"][0]
+		assert.Equal(t, 1024.0, point.value)
+		assert.True(t, point.monotonic)
+		assert.Equal(t, uint64(start.UnixNano()), point.start)
+	}
+	if assert.Len(t, points["test_rtt"], 1) {
+		point := points["test_rtt"][0]
+		assert.Equal(t, map[string]string{"service.name": otlpScopeName}, point.resource)
+		assert.Equal(t, uint64(3), point.count)
+		assert.Equal(t, 55.5, point.value)
+		assert.Equal(t, []uint64{1, 1, 1}, point.buckets)
+		assert.Equal(t, []float64{1, 10}, point.bounds)
+	}
+}
+
+func TestOtlpSourceTimestamps(t *testing.T) {
+	logger.Setup("", "ERROR")
+	registry := prometheus.NewRegistry()
+	vec := newGaugeVec(prometheus.GaugeOpts{Name: "kte_test_otlp_power", Help: "test"}, []string{"device_id", "title"})
+	defer func() {
+		metricFamilies.Lock()
+		delete(metricFamilies.vecs, "kte_test_otlp_power")
+		metricFamilies.Unlock()
+	}()
+	registry.MustRegister(vec)
+
+	cache := newSourceTimestampCache()
+	sinks := metricSinks
+	metricSinks = []Sink{prometheusSink{}, cache}
+	defer func() { metricSinks = sinks }()
+
+	// two groups of the same device, reported at different times
+	first := time.Unix(1636106400, 0)
+	second := time.Unix(1636106460, 0)
+	cache.observe("onu-1", first)
+	vec.WithLabelValues("onu-1", "PON_Optical").Set(1)
+	cache.observe("onu-1", second)
+	vec.WithLabelValues("onu-1", "UNI_Status").Set(2)
+	// not reported by its device
+	vec.WithLabelValues("", "PON_Optical").Set(3)
+
+	mfs, err := (&sourceTimestampGatherer{Gatherer: registry, cache: cache}).Gather()
+	assert.NoError(t, err)
+	now := time.Unix(1636107000, 0)
+	times := make(map[string]uint64)
+	for _, point := range decodeOtlpRequest(t, encodeOtlpMetrics(mfs, map[string]bool{}, now, now))["kte_test_otlp_power"] {
+		times[point.attributes["device_id"]+"/"+point.attributes["title"]] = point.time
+	}
+	assert.Equal(t, map[string]uint64{
+		"onu-1/PON_Optical": uint64(first.UnixNano()),
+		"onu-1/UNI_Status":  uint64(second.UnixNano()),
+		"/PON_Optical":      uint64(now.UnixNano()),
+	}, times)
+
+	// deleted series and silent devices are forgotten
+	vec.DeleteLabelValues("onu-1", "UNI_Status")
+	_, ok := cache.get("kte_test_otlp_power", sampleLabelPairs([]string{"device_id", "title"}, []string{"onu-1", "UNI_Status"}))
+	assert.False(t, ok)
+	cache.expire(second.Add(time.Second))
+	assert.Empty(t, cache.series)
+	assert.Empty(t, cache.devices)
+}
+
+func TestOtlpExportHttp(t *testing.T) {
+	logger.Setup("", "ERROR")
+	var received []byte
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		headers = req.Header
+		received, _ = ioutil.ReadAll(req.Body)
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_gauge", Help: "test"})
+	registry.MustRegister(gauge)
+	gauge.Set(42)
+
+	exporter, err := newOtlpExporter(OtlpInfo{Protocol: otlpProtocolHttp, Endpoint: server.URL, Headers: map[string]string{"X-Scope-OrgID": "lab1"}}, registry)
+	assert.NoError(t, err)
+	assert.NoError(t, exporter.export())
+
+	assert.Equal(t, "application/x-protobuf", headers.Get("Content-Type"))
+	assert.Equal(t, "lab1", headers.Get("X-Scope-OrgID"))
+	points := decodeOtlpRequest(t, received)
+	if assert.Len(t, points["test_gauge"], 1) {
+		assert.Equal(t, 42.0, points["test_gauge"][0].value)
+	}
+}
+
+func TestOtlpExportGrpc(t *testing.T) {
+	logger.Setup("", "ERROR")
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	var method string
+	var received []byte
+	var md metadata.MD
+	server := grpc.NewServer(grpc.ForceServerCodec(otlpRawCodec{}), grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		method, _ = grpc.MethodFromServerStream(stream)
+		md, _ = metadata.FromIncomingContext(stream.Context())
+		if err := stream.RecvMsg(&received); err != nil {
+			return err
+		}
+		response := []byte{}
+		return stream.SendMsg(&response)
+	}))
+	go server.Serve(listener)
+	defer server.Stop()
+
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_gauge", Help: "test"})
+	registry.MustRegister(gauge)
+	gauge.Set(42)
+
+	exporter, err := newOtlpExporter(OtlpInfo{Endpoint: listener.Addr().String(), Insecure: true, Headers: map[string]string{"x-scope-orgid": "lab1"}}, registry)
+	assert.NoError(t, err)
+	assert.NoError(t, exporter.export())
+
+	assert.Equal(t, otlpExportMethod, method)
+	assert.Equal(t, []string{"lab1"}, md.Get("x-scope-orgid"))
+	points := decodeOtlpRequest(t, received)
+	if assert.Len(t, points["test_gauge"], 1) {
+		assert.Equal(t, 42.0, points["test_gauge"][0].value)
+	}
+}
+
+func TestOtlpRawCodec(t *testing.T) {
+	codec := otlpRawCodec{}
+	request := []byte{0x0a, 0x00}
+	data, err := codec.Marshal(&request)
+	assert.NoError(t, err)
+	assert.Equal(t, request, data)
+
+	var response []byte
+	assert.NoError(t, codec.Unmarshal(data, &response))
+	assert.Equal(t, request, response)
+	assert.Equal(t, "proto", codec.Name())
+}
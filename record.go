@@ -0,0 +1,215 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/Shopify/sarama"
+)
+
+const (
+	// apart from the exporter one, so that recording takes nothing from it
+	cDefaultRecordGroup   = "kte_record_grp"
+	cDefaultRecordMaxSize = 100 * 1024 * 1024
+	cDefaultRecordMaxAge  = time.Hour
+)
+
+// recordWriter writes the messages to gzipped JSONL files, a new file being
+// started once the current one reaches the size or age limit
+type recordWriter struct {
+	sync.Mutex
+	dir     string
+	maxSize int64
+	maxAge  time.Duration
+
+	file    *os.File
+	gz      *gzip.Writer
+	opened  time.Time
+	written int64
+	// number of the files started, keeping apart the ones started within
+	// the same millisecond
+	seq int
+	// no more messages are recorded once closed
+	closed bool
+}
+
+func newRecordWriter(dir string, maxSize int64, maxAge time.Duration) (*recordWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &recordWriter{dir: dir, maxSize: maxSize, maxAge: maxAge}, nil
+}
+
+// write records a message, the write errors are logged rather than stopping
+// the recording. It reports false when the message could not be recorded,
+// no file being open or the writer closed, for its offset not to be
+// committed.
+func (w *recordWriter) write(message *sarama.ConsumerMessage) bool {
+	line, err := json.Marshal(recordedMessage{
+		Topic:     message.Topic,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+		Key:       string(message.Key),
+		Value:     message.Value,
+		Timestamp: message.Timestamp,
+	})
+	if err != nil {
+		logger.Error("Cannot encode the message [%s/%d/%d]: %s", message.Topic, message.Partition, message.Offset, err.Error())
+		return true
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	if w.closed {
+		return false
+	}
+	if w.file != nil && (w.written >= w.maxSize || time.Since(w.opened) >= w.maxAge) {
+		w.close()
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			logger.Error("Cannot start a recording file in [%s]: %s", w.dir, err.Error())
+			return false
+		}
+	}
+	n, err := w.gz.Write(append(line, '\n'))
+	if err != nil {
+		logger.Error("Cannot write to [%s]: %s", w.file.Name(), err.Error())
+	}
+	// the uncompressed size, the compressed one is only known once flushed
+	w.written += int64(n)
+	return true
+}
+
+// open starts a file named after the current time and its sequence number,
+// e.g. kte-record-20211105T100000.000Z-0001.jsonl.gz
+func (w *recordWriter) open() error {
+	w.opened = time.Now().UTC()
+	w.seq++
+	name := filepath.Join(w.dir, fmt.Sprintf("kte-record-%s-%04d.jsonl.gz", w.opened.Format("20060102T150405.000Z"), w.seq))
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	w.file = file
+	w.gz = gzip.NewWriter(file)
+	w.written = 0
+	logger.Info("Recording to [%s]", name)
+	return nil
+}
+
+func (w *recordWriter) close() {
+	if w.file == nil {
+		return
+	}
+	if err := w.gz.Close(); err != nil {
+		logger.Error("Cannot complete [%s]: %s", w.file.Name(), err.Error())
+	}
+	if err := w.file.Close(); err != nil {
+		logger.Error("Cannot close [%s]: %s", w.file.Name(), err.Error())
+	}
+	w.file = nil
+	w.gz = nil
+}
+
+// Close completes the current file and stops the recording
+func (w *recordWriter) Close() {
+	w.Lock()
+	defer w.Unlock()
+	w.close()
+	w.closed = true
+}
+
+// recordCommand consumes the configured topics and records every message,
+// until interrupted or for the given duration
+//
+//	kafka-topic-exporter record [-config file] [-dir dir] [-max-size bytes] [-max-age duration] [-duration duration]
+func recordCommand(args []string) int {
+	flags := flag.NewFlagSet("record", flag.ContinueOnError)
+	configFile := flags.String("config", cDefaultConfigFile, "configuration file")
+	dir := flags.String("dir", ".", "directory the recordings are written to")
+	group := flags.String("group", cDefaultRecordGroup, "consumer group")
+	topicList := flags.String("topics", "", "comma separated topics, the configured ones by default")
+	maxSize := flags.Int64("max-size", cDefaultRecordMaxSize, "uncompressed size, in bytes, after which a new file is started")
+	maxAge := flags.Duration("max-age", cDefaultRecordMaxAge, "age after which a new file is started")
+	duration := flags.Duration("duration", 0, "stop recording after this long, 0 to record until interrupted")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s record [flags]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	conf := loadConfigFile(*configFile)
+	logger.Setup(conf.Logger.Host, strings.ToUpper(conf.Logger.LogLevel))
+
+	topics := conf.Broker.Topics
+	if *topicList != "" {
+		topics = strings.Split(*topicList, ",")
+	}
+	if len(topics) == 0 {
+		logger.Error("No topic to record")
+		return 2
+	}
+
+	writer, err := newRecordWriter(*dir, *maxSize, *maxAge)
+	if err != nil {
+		logger.Error("Cannot record to [%s]: %s", *dir, err.Error())
+		return 1
+	}
+
+	config := sarama.NewConfig()
+	config.Consumer.Return.Errors = true
+	config.Metadata.AllowAutoTopicCreation = false
+	consumer, err := sarama.NewConsumerGroup([]string{conf.Broker.Host}, *group, config)
+	if err != nil {
+		logger.Error("Cannot join [%s] on [%s]: %s", *group, conf.Broker.Host, err.Error())
+		return 1
+	}
+	defer consumer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if *duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	logger.Info("Recording %s from [%s] to [%s]", topics, conf.Broker.Host, *dir)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go topicListener(ctx, topics, consumer, &wg, &Consumer{HandleMessage: writer.write})
+	wg.Wait()
+	// the offsets are committed as the group is left, before the last file
+	// is completed
+	if err := consumer.Close(); err != nil {
+		logger.Error("Cannot leave [%s]: %s", *group, err.Error())
+	}
+	writer.Close()
+	return 0
+}
@@ -0,0 +1,124 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordWriterRotation(t *testing.T) {
+	logger.Setup("", "ERROR")
+	dir := t.TempDir()
+	// every message fills a file
+	writer, err := newRecordWriter(dir, 1, time.Hour)
+	assert.NoError(t, err)
+
+	for offset := int64(0); offset < 3; offset++ {
+		writer.write(&sarama.ConsumerMessage{
+			Topic:     "onos.kpis",
+			Partition: 2,
+			Offset:    offset,
+			Key:       []byte("of:1"),
+			Value:     []byte(`{"deviceId":"of:1"}`),
+			Timestamp: time.Date(2021, 11, 5, 10, 0, 0, 0, time.UTC),
+		})
+	}
+	writer.Close()
+	// ignored once closed
+	writer.write(&sarama.ConsumerMessage{Topic: "onos.kpis"})
+
+	files, err := filepath.Glob(filepath.Join(dir, "kte-record-*.jsonl.gz"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 3)
+	for i, name := range files {
+		assert.True(t, strings.HasSuffix(name, fmt.Sprintf("-%04d.jsonl.gz", i+1)), name)
+	}
+
+	for _, name := range files {
+		file, err := os.Open(name)
+		assert.NoError(t, err)
+		gz, err := gzip.NewReader(file)
+		assert.NoError(t, err)
+		count, err := replayMessages(gz, func(topic *string, data []byte) {
+			assert.Equal(t, "onos.kpis", *topic)
+			assert.Equal(t, `{"deviceId":"of:1"}`, string(data))
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		file.Close()
+	}
+}
+
+func TestRecordCommitsRecordedOnly(t *testing.T) {
+	logger.Setup("", "ERROR")
+	writer, err := newRecordWriter(t.TempDir(), cDefaultRecordMaxSize, time.Hour)
+	assert.NoError(t, err)
+	session := &fakeConsumerGroupSession{}
+	consumer := &Consumer{HandleMessage: writer.write}
+
+	claim := newFakeConsumerGroupClaim("onos.kpis", [][]byte{[]byte(`{"deviceId":"of:1"}`)})
+	assert.NoError(t, consumer.ConsumeClaim(session, claim))
+	writer.Close()
+	// the messages handled once closed are not recorded, their offset is
+	// not committed
+	claim = newFakeConsumerGroupClaim("onos.kpis", [][]byte{[]byte(`{"deviceId":"of:2"}`)})
+	assert.NoError(t, consumer.ConsumeClaim(session, claim))
+	assert.Len(t, session.marked, 1)
+}
+
+// sessionConsumerGroup is a consumer group whose session outlives the
+// context by a little
+type sessionConsumerGroup struct {
+	sarama.ConsumerGroup
+	sync.Mutex
+	released bool
+}
+
+func (g *sessionConsumerGroup) Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error {
+	<-ctx.Done()
+	time.Sleep(10 * time.Millisecond)
+	g.Lock()
+	defer g.Unlock()
+	g.released = true
+	return nil
+}
+
+func TestTopicListenerWaitsForTheSession(t *testing.T) {
+	logger.Setup("", "ERROR")
+	group := &sessionConsumerGroup{}
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go topicListener(ctx, []string{"onos.kpis"}, group, &wg, &Consumer{HandleFunc: export})
+	cancel()
+	wg.Wait()
+
+	// nothing is handled once the listener is done
+	group.Lock()
+	defer group.Unlock()
+	assert.True(t, group.released)
+}
@@ -0,0 +1,263 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// relabel actions, as in the Prometheus metric_relabel_configs
+const (
+	relabelReplace   = "replace"
+	relabelKeep      = "keep"
+	relabelDrop      = "drop"
+	relabelLabelDrop = "labeldrop"
+	relabelLabelKeep = "labelkeep"
+
+	metricNameLabel = "__name__"
+)
+
+type relabelRule struct {
+	action       string
+	sourceLabels []string
+	separator    string
+	regex        *regexp.Regexp
+	targetLabel  string
+	replacement  string
+}
+
+// compileRelabelRules checks the rules and fills in the Prometheus defaults
+func compileRelabelRules(rules []RelabelRule) ([]*relabelRule, error) {
+	compiled := make([]*relabelRule, 0, len(rules))
+	for i, rule := range rules {
+		r := &relabelRule{
+			action:       strings.ToLower(rule.Action),
+			sourceLabels: rule.SourceLabels,
+			separator:    rule.Separator,
+			targetLabel:  rule.TargetLabel,
+			replacement:  rule.Replacement,
+		}
+		if r.action == "" {
+			r.action = relabelReplace
+		}
+		if r.separator == "" {
+			r.separator = ";"
+		}
+		if r.replacement == "" {
+			r.replacement = "$1"
+		}
+		expr := rule.Regex
+		if expr == "" {
+			expr = "(.*)"
+		}
+		regex, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("relabel rule %d: %s", i, err.Error())
+		}
+		r.regex = regex
+
+		switch r.action {
+		case relabelReplace:
+			if r.targetLabel == "" || r.targetLabel == metricNameLabel {
+				return nil, fmt.Errorf("relabel rule %d: replace needs a target_label other than %s", i, metricNameLabel)
+			}
+		case relabelKeep, relabelDrop:
+			if len(r.sourceLabels) == 0 {
+				return nil, fmt.Errorf("relabel rule %d: %s needs source_labels", i, r.action)
+			}
+		case relabelLabelDrop, relabelLabelKeep:
+		default:
+			return nil, fmt.Errorf("relabel rule %d: unknown action [%s]", i, rule.Action)
+		}
+		compiled = append(compiled, r)
+	}
+	return compiled, nil
+}
+
+// byNameOnly tells whether the rule only depends on the metric name, in
+// which case it can be applied to whole families
+func (r *relabelRule) byNameOnly() bool {
+	return (r.action == relabelKeep || r.action == relabelDrop) &&
+		len(r.sourceLabels) == 1 && r.sourceLabels[0] == metricNameLabel
+}
+
+// familyDropped tells whether the rules drop every series of the family
+func familyDropped(rules []*relabelRule, name string) bool {
+	for _, rule := range rules {
+		if !rule.byNameOnly() {
+			continue
+		}
+		if rule.regex.MatchString(name) == (rule.action == relabelDrop) {
+			return true
+		}
+	}
+	return false
+}
+
+// relabel applies the rules to the labels of a series, the metric name
+// being in __name__. It returns false when the series is dropped.
+func relabel(rules []*relabelRule, labels map[string]string) bool {
+	for _, rule := range rules {
+		values := make([]string, 0, len(rule.sourceLabels))
+		for _, name := range rule.sourceLabels {
+			values = append(values, labels[name])
+		}
+		value := strings.Join(values, rule.separator)
+
+		switch rule.action {
+		case relabelKeep:
+			if !rule.regex.MatchString(value) {
+				return false
+			}
+		case relabelDrop:
+			if rule.regex.MatchString(value) {
+				return false
+			}
+		case relabelReplace:
+			indexes := rule.regex.FindStringSubmatchIndex(value)
+			if indexes == nil {
+				continue
+			}
+			result := string(rule.regex.ExpandString(nil, rule.replacement, value, indexes))
+			if result == "" {
+				delete(labels, rule.targetLabel)
+			} else {
+				labels[rule.targetLabel] = result
+			}
+		case relabelLabelDrop, relabelLabelKeep:
+			for name := range labels {
+				if name == metricNameLabel {
+					continue
+				}
+				if rule.regex.MatchString(name) == (rule.action == relabelLabelDrop) {
+					delete(labels, name)
+				}
+			}
+		}
+	}
+	return true
+}
+
+var descNameRegexp = regexp.MustCompile(`^Desc{fqName: "([^"]*)"`)
+
+// relabelRegisterer does not register the collectors whose families are
+// all dropped by the rules, so that they are never exported
+type relabelRegisterer struct {
+	prometheus.Registerer
+	rules []*relabelRule
+}
+
+// Register implements prometheus.Registerer
+func (r *relabelRegisterer) Register(c prometheus.Collector) error {
+	descs := make(chan *prometheus.Desc)
+	go func() {
+		c.Describe(descs)
+		close(descs)
+	}()
+	dropped := true
+	var names []string
+	for desc := range descs {
+		match := descNameRegexp.FindStringSubmatch(desc.String())
+		if match == nil || !familyDropped(r.rules, match[1]) {
+			dropped = false
+		}
+		if match != nil {
+			names = append(names, match[1])
+		}
+	}
+	if dropped && len(names) > 0 {
+		logger.Debug("Not registering %s, dropped by the relabel rules", names)
+		return nil
+	}
+	return r.Registerer.Register(c)
+}
+
+// MustRegister implements prometheus.Registerer
+func (r *relabelRegisterer) MustRegister(cs ...prometheus.Collector) {
+	for _, c := range cs {
+		if err := r.Register(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// relabelGatherer applies the rules to the gathered series. Series made
+// identical by the rules are only exported once.
+type relabelGatherer struct {
+	prometheus.Gatherer
+	rules []*relabelRule
+}
+
+// Gather implements prometheus.Gatherer
+func (g *relabelGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	kept := mfs[:0]
+	for _, mf := range mfs {
+		if familyDropped(g.rules, mf.GetName()) {
+			continue
+		}
+		seen := make(map[string]bool)
+		metrics := mf.Metric[:0]
+		for _, m := range mf.GetMetric() {
+			labels := map[string]string{metricNameLabel: mf.GetName()}
+			for _, pair := range m.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+			if !relabel(g.rules, labels) {
+				continue
+			}
+			delete(labels, metricNameLabel)
+
+			m.Label = m.Label[:0]
+			for name, value := range labels {
+				m.Label = append(m.Label, labelPair(name, value))
+			}
+			sort.Slice(m.Label, func(i, j int) bool {
+				return m.Label[i].GetName() < m.Label[j].GetName()
+			})
+
+			signature := labelsSignature(m.Label)
+			if seen[signature] {
+				continue
+			}
+			seen[signature] = true
+			metrics = append(metrics, m)
+		}
+		if len(metrics) == 0 {
+			continue
+		}
+		mf.Metric = metrics
+		kept = append(kept, mf)
+	}
+	return kept, err
+}
+
+func labelsSignature(labels []*dto.LabelPair) string {
+	var signature strings.Builder
+	for _, pair := range labels {
+		signature.WriteString(pair.GetName())
+		signature.WriteByte(0xff)
+		signature.WriteString(pair.GetValue())
+		signature.WriteByte(0xff)
+	}
+	return signature.String()
+}
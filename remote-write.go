@@ -0,0 +1,301 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	cDefaultRemoteWriteInterval     = 15 * time.Second
+	cDefaultRemoteWriteTimeout      = 10 * time.Second
+	cDefaultRemoteWriteBatchSize    = 500
+	cDefaultRemoteWriteQueueSize    = 10000
+	cDefaultRemoteWriteMaxRetries   = 3
+	cDefaultRemoteWriteRetryBackoff = time.Second
+)
+
+var (
+	remoteWriteSentSamples = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kte_remote_write_sent_samples_total",
+			Help: "Samples sent to the remote-write endpoint",
+		})
+	remoteWriteFailedSamples = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kte_remote_write_failed_samples_total",
+			Help: "Samples that could not be sent to the remote-write endpoint",
+		})
+	remoteWriteDroppedSamples = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kte_remote_write_dropped_samples_total",
+			Help: "Samples dropped because the remote-write queue was full",
+		})
+	remoteWriteQueueLength = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kte_remote_write_queue_length",
+			Help: "Samples waiting to be sent to the remote-write endpoint",
+		})
+)
+
+// remoteWriteQueue is a bounded in-memory queue of samples, the oldest ones
+// are dropped when it is full
+type remoteWriteQueue struct {
+	sync.Mutex
+	samples []*sample
+	limit   int
+	// signaled when samples are pushed
+	ready chan struct{}
+}
+
+func newRemoteWriteQueue(limit int) *remoteWriteQueue {
+	return &remoteWriteQueue{
+		limit: limit,
+		ready: make(chan struct{}, 1),
+	}
+}
+
+// push queues the samples and returns how many were dropped
+func (q *remoteWriteQueue) push(samples []*sample) int {
+	q.Lock()
+	defer q.Unlock()
+
+	q.samples = append(q.samples, samples...)
+	dropped := 0
+	if len(q.samples) > q.limit {
+		dropped = len(q.samples) - q.limit
+		q.samples = append([]*sample{}, q.samples[dropped:]...)
+	}
+	remoteWriteQueueLength.Set(float64(len(q.samples)))
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return dropped
+}
+
+// pop dequeues up to max samples
+func (q *remoteWriteQueue) pop(max int) []*sample {
+	q.Lock()
+	defer q.Unlock()
+
+	if max > len(q.samples) {
+		max = len(q.samples)
+	}
+	batch := q.samples[:max]
+	q.samples = q.samples[max:]
+	remoteWriteQueueLength.Set(float64(len(q.samples)))
+	return batch
+}
+
+// remoteWriter periodically pushes the gathered series to a Prometheus
+// remote-write endpoint
+type remoteWriter struct {
+	conf     RemoteWriteInfo
+	gatherer prometheus.Gatherer
+	client   *http.Client
+	queue    *remoteWriteQueue
+}
+
+func newRemoteWriter(conf RemoteWriteInfo, gatherer prometheus.Gatherer) *remoteWriter {
+	if conf.Interval == 0 {
+		conf.Interval = cDefaultRemoteWriteInterval
+	}
+	if conf.Timeout == 0 {
+		conf.Timeout = cDefaultRemoteWriteTimeout
+	}
+	if conf.BatchSize == 0 {
+		conf.BatchSize = cDefaultRemoteWriteBatchSize
+	}
+	if conf.QueueSize == 0 {
+		conf.QueueSize = cDefaultRemoteWriteQueueSize
+	}
+	if conf.MaxRetries == 0 {
+		conf.MaxRetries = cDefaultRemoteWriteMaxRetries
+	}
+	if conf.RetryBackoff == 0 {
+		conf.RetryBackoff = cDefaultRemoteWriteRetryBackoff
+	}
+	return &remoteWriter{
+		conf:     conf,
+		gatherer: gatherer,
+		client:   &http.Client{Timeout: conf.Timeout},
+		queue:    newRemoteWriteQueue(conf.QueueSize),
+	}
+}
+
+// run snapshots the series every interval, the sending is done apart so
+// that a slow endpoint does not delay the snapshots
+func (w *remoteWriter) run() {
+	go func() {
+		for range w.queue.ready {
+			w.flush()
+		}
+	}()
+
+	ticker := time.NewTicker(w.conf.Interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		w.snapshot()
+	}
+}
+
+// snapshot queues the current value of every gathered series
+func (w *remoteWriter) snapshot() {
+	mfs, err := w.gatherer.Gather()
+	if err != nil {
+		logger.Warn("Errors gathering the metrics for remote-write: %s", err.Error())
+	}
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	var samples []*sample
+	for _, mf := range mfs {
+		samples = append(samples, familySamples(mf, now)...)
+	}
+	if dropped := w.queue.push(samples); dropped > 0 {
+		logger.Warn("Remote-write queue full, dropped [%d] samples", dropped)
+		remoteWriteDroppedSamples.Add(float64(dropped))
+	}
+}
+
+// flush sends the queued samples in batches
+func (w *remoteWriter) flush() {
+	for {
+		batch := w.queue.pop(w.conf.BatchSize)
+		if len(batch) == 0 {
+			return
+		}
+		if err := w.send(batch); err != nil {
+			logger.Error("Cannot send [%d] samples to [%s]: %s", len(batch), w.conf.Url, err.Error())
+			remoteWriteFailedSamples.Add(float64(len(batch)))
+			continue
+		}
+		remoteWriteSentSamples.Add(float64(len(batch)))
+	}
+}
+
+// send posts a batch, retrying with an exponential backoff on the errors
+// that may be temporary
+func (w *remoteWriter) send(batch []*sample) error {
+	body := snappy.Encode(nil, encodeWriteRequest(batch))
+	backoff := w.conf.RetryBackoff
+	var err error
+	for attempt := 0; attempt <= w.conf.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		var retry bool
+		if retry, err = w.post(body); err == nil || !retry {
+			return err
+		}
+		logger.Debug("Remote-write attempt [%d] failed: %s", attempt+1, err.Error())
+	}
+	return err
+}
+
+// post sends the request once, it tells whether a failure is worth a retry
+func (w *remoteWriter) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.conf.Url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "kafka-topic-exporter")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	for name, value := range w.conf.Headers {
+		req.Header.Set(name, value)
+	}
+	if w.conf.BasicAuth.Username != "" {
+		req.SetBasicAuth(w.conf.BasicAuth.Username, w.conf.BasicAuth.Password)
+	}
+	if w.conf.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+w.conf.BearerToken)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	err = fmt.Errorf("server returned %s: %s", resp.Status, bytes.TrimSpace(message))
+	return resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests, err
+}
+
+// encodeWriteRequest encodes the samples as a prometheus.WriteRequest, one
+// TimeSeries per sample:
+//
+//	WriteRequest { repeated TimeSeries timeseries = 1; }
+//	TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	Label        { string name = 1; string value = 2; }
+//	Sample       { double value = 1; int64 timestamp = 2; }
+func encodeWriteRequest(samples []*sample) []byte {
+	var request []byte
+	for _, s := range samples {
+		// the labels must be sorted, __name__ included
+		var series []byte
+		nameAdded := false
+		for _, pair := range s.labels {
+			if !nameAdded && pair.GetName() > metricNameLabel {
+				series = appendRemoteWriteLabel(series, metricNameLabel, s.name)
+				nameAdded = true
+			}
+			series = appendRemoteWriteLabel(series, pair.GetName(), pair.GetValue())
+		}
+		if !nameAdded {
+			series = appendRemoteWriteLabel(series, metricNameLabel, s.name)
+		}
+
+		var value []byte
+		value = protowire.AppendTag(value, 1, protowire.Fixed64Type)
+		value = protowire.AppendFixed64(value, math.Float64bits(s.value))
+		value = protowire.AppendTag(value, 2, protowire.VarintType)
+		value = protowire.AppendVarint(value, uint64(s.timestamp))
+		series = protowire.AppendTag(series, 2, protowire.BytesType)
+		series = protowire.AppendBytes(series, value)
+
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, series)
+	}
+	return request
+}
+
+func appendRemoteWriteLabel(b []byte, name string, value string) []byte {
+	var label []byte
+	label = protowire.AppendTag(label, 1, protowire.BytesType)
+	label = protowire.AppendString(label, name)
+	label = protowire.AppendTag(label, 2, protowire.BytesType)
+	label = protowire.AppendString(label, value)
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, label)
+}
@@ -0,0 +1,147 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// remoteWriteReceiver is a stand-in remote-write endpoint decoding the
+// received series
+type remoteWriteReceiver struct {
+	sync.Mutex
+	series    []map[string]string
+	values    []float64
+	headers   http.Header
+	failFirst int
+	requests  int
+}
+
+func (r *remoteWriteReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.requests++
+	if r.requests <= r.failFirst {
+		http.Error(w, "not now", http.StatusServiceUnavailable)
+		return
+	}
+	r.headers = req.Header
+	compressed, _ := ioutil.ReadAll(req.Body)
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	forEachField(data, func(_ protowire.Number, series []byte) {
+		labels := map[string]string{}
+		var value float64
+		forEachField(series, func(num protowire.Number, field []byte) {
+			switch num {
+			case 1:
+				var name, val string
+				forEachField(field, func(num protowire.Number, s []byte) {
+					if num == 1 {
+						name = string(s)
+					} else {
+						val = string(s)
+					}
+				})
+				labels[name] = val
+			case 2:
+				bits, _ := protowire.ConsumeFixed64(field[1:])
+				value = math.Float64frombits(bits)
+			}
+		})
+		r.series = append(r.series, labels)
+		r.values = append(r.values, value)
+	})
+}
+
+// forEachField calls fn with the length-delimited fields of a message
+func forEachField(b []byte, fn func(protowire.Number, []byte)) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		b = b[n:]
+		switch typ {
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			fn(num, v)
+			b = b[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			b = b[n:]
+		}
+	}
+}
+
+func TestRemoteWriter(t *testing.T) {
+	logger.Setup("", "ERROR")
+	receiver := &remoteWriteReceiver{failFirst: 1}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_gauge", Help: "test"}, []string{"serial_number"})
+	registry.MustRegister(gauge)
+	gauge.WithLabelValues("BBSM00000001").Set(42)
+	gauge.WithLabelValues("BBSM00000002").Set(7)
+
+	writer := newRemoteWriter(RemoteWriteInfo{
+		Url:          server.URL,
+		BatchSize:    1,
+		RetryBackoff: time.Millisecond,
+		Headers:      map[string]string{"X-Scope-OrgID": "lab1"},
+		BearerToken:  "secret",
+	}, registry)
+	writer.snapshot()
+	writer.flush()
+
+	receiver.Lock()
+	defer receiver.Unlock()
+	// one failed attempt, then a request per sample
+	assert.Equal(t, 3, receiver.requests)
+	assert.Equal(t, []map[string]string{
+		{"__name__": "test_gauge", "serial_number": "BBSM00000001"},
+		{"__name__": "test_gauge", "serial_number": "BBSM00000002"},
+	}, receiver.series)
+	assert.Equal(t, []float64{42, 7}, receiver.values)
+	assert.Equal(t, "snappy", receiver.headers.Get("Content-Encoding"))
+	assert.Equal(t, "lab1", receiver.headers.Get("X-Scope-OrgID"))
+	assert.Equal(t, "Bearer secret", receiver.headers.Get("Authorization"))
+}
+
+func TestRemoteWriteQueueLimit(t *testing.T) {
+	queue := newRemoteWriteQueue(2)
+	dropped := queue.push([]*sample{{name: "a"}, {name: "b"}, {name: "c"}})
+	assert.Equal(t, 1, dropped)
+
+	batch := queue.pop(10)
+	assert.Len(t, batch, 2)
+	assert.Equal(t, "b", batch[0].name)
+	assert.Empty(t, queue.pop(10))
+}
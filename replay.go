@@ -0,0 +1,175 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// a recorded line holds a whole message, base64 encoded
+const cMaxRecordedMessageSize = 16 * 1024 * 1024
+
+// recordedMessage is a Kafka message as recorded, one JSON object per line.
+// The value is base64 encoded.
+type recordedMessage struct {
+	Topic     string    `json:"topic"`
+	Partition int32     `json:"partition"`
+	Offset    int64     `json:"offset"`
+	Key       string    `json:"key,omitempty"`
+	Value     []byte    `json:"value"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// replayCommand feeds recorded messages through export, then dumps the
+// resulting metrics or serves them along with the configured outputs
+//
+//	kafka-topic-exporter replay [-config file] [-output file] [-port port] file...
+func replayCommand(args []string) int {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	configFile := flags.String("config", cDefaultConfigFile, "configuration file")
+	output := flags.String("output", "-", "file the metrics are dumped to, - for the standard output")
+	port := flags.Int("port", 0, "serve /metrics on this port after the replay rather than dumping them")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s replay [flags] file...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	conf := loadConfigFile(*configFile)
+	logger.Setup(conf.Logger.Host, strings.ToUpper(conf.Logger.LogLevel))
+	if err := setupPipeline(conf); err != nil {
+		logger.Error("Invalid configuration: %s", err.Error())
+		return 1
+	}
+	if *port != 0 {
+		// the Kafka and InfluxDB outputs get the samples with the time they
+		// were recorded at, the others push or serve the final values
+		startOutputs(conf)
+	}
+
+	for _, file := range flags.Args() {
+		count, err := replayFile(file)
+		if err != nil {
+			logger.Error("Cannot replay [%s]: %s", file, err.Error())
+			return 1
+		}
+		logger.Info("Replayed [%d] messages from [%s]", count, file)
+	}
+
+	if *port != 0 {
+		conf.Target.Port = *port
+		runServer(conf.Target)
+		return 1
+	}
+
+	w := os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			logger.Error("Cannot create [%s]: %s", *output, err.Error())
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := dumpMetrics(w, metricsGatherer); err != nil {
+		logger.Error("Cannot dump the metrics: %s", err.Error())
+		return 1
+	}
+	return 0
+}
+
+// replayFile exports the messages of a recording, in order
+func replayFile(name string) (int, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	// as written by the record subcommand
+	if strings.HasSuffix(name, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return 0, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return replayMessages(r, export)
+}
+
+// replayMessages calls handle with every message read, the invalid lines
+// are logged and skipped. The samples written meanwhile carry the time the
+// message was recorded at.
+func replayMessages(r io.Reader, handle func(topic *string, data []byte)) (int, error) {
+	clock := sampleClock
+	defer func() { sampleClock = clock }()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), cMaxRecordedMessageSize)
+	count := 0
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		message := recordedMessage{}
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			logger.Warn("Skipping line [%d]: %s", line, err.Error())
+			continue
+		}
+		sampleClock = clock
+		if recorded := message.Timestamp; !recorded.IsZero() {
+			sampleClock = func() time.Time { return recorded }
+		}
+		handle(&message.Topic, message.Value)
+		count++
+	}
+	return count, scanner.Err()
+}
+
+// dumpMetrics writes the gathered metrics in the text exposition format
+func dumpMetrics(w io.Writer, gatherer prometheus.Gatherer) error {
+	mfs, err := gatherer.Gather()
+	if err != nil {
+		logger.Warn("Errors gathering the metrics: %s", err.Error())
+	}
+	encoder := expfmt.NewEncoder(w, expfmt.FmtText)
+	for _, mf := range mfs {
+		if err := encoder.Encode(mf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayMessages(t *testing.T) {
+	logger.Setup("", "ERROR")
+	recording := strings.Join([]string{
+		`{"topic":"onos.kpis","partition":0,"offset":7,"value":"eyJkZXZpY2VJZCI6Im9mOjEifQ==","timestamp":"2021-11-05T10:00:00Z"}`,
+		`not a message`,
+		``,
+		`{"topic":"bng.stats","value":"e30="}`,
+	}, "\n")
+
+	var topics, values []string
+	count, err := replayMessages(strings.NewReader(recording), func(topic *string, data []byte) {
+		topics = append(topics, *topic)
+		values = append(values, string(data))
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []string{"onos.kpis", "bng.stats"}, topics)
+	assert.Equal(t, []string{`{"deviceId":"of:1"}`, `{}`}, values)
+}
+
+func TestReplayFile(t *testing.T) {
+	logger.Setup("", "ERROR")
+	registry := newTestRegistry()
+	recorded := &recordingSink{}
+	defer func(sinks []Sink) { metricSinks = sinks }(metricSinks)
+	metricSinks = []Sink{prometheusSink{}, recorded}
+
+	count, err := replayFile(filepath.Join("testdata", "replay", "recording.jsonl"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assertGolden(t, registry, "replay")
+
+	// the samples carry the time the messages were recorded at
+	stamps := make(map[string][]time.Time)
+	for _, s := range recorded.samples {
+		stamps[s.Name] = append(stamps[s.Name], s.Timestamp)
+	}
+	assert.Equal(t, []time.Time{
+		time.Date(2021, 11, 5, 10, 0, 0, 0, time.UTC),
+		time.Date(2021, 11, 5, 10, 0, 15, 0, time.UTC),
+	}, stamps["onos_rx_bytes_total"])
+	assert.Equal(t, []time.Time{time.Date(2021, 11, 5, 10, 0, 20, 0, time.UTC)}, stamps["bng_up_tx_bytes_total"])
+	assert.NotEqual(t, time.Date(2021, 11, 5, 10, 0, 20, 0, time.UTC), sampleClock())
+}
@@ -0,0 +1,250 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"gerrit.opencord.org/kafka-topic-exporter/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	cDefaultSadisRefresh = 5 * time.Minute
+	sadisHTTPTimeout     = 30 * time.Second
+)
+
+// SadisSubscriber is a subscriber entry of a SADIS database, its id (or
+// NAS port id) being the name of the UNI port, e.g. 'BBSM00000001-1'
+type SadisSubscriber struct {
+	ID        string `json:"id"`
+	NasPortID string `json:"nasPortId"`
+	CircuitID string `json:"circuitId"`
+	RemoteID  string `json:"remoteId"`
+}
+
+// sadisDatabase accepts the entries at the top level, as served by the
+// SADIS HTTP integration, or under "sadis" as in the ONOS network config
+type sadisDatabase struct {
+	Entries []*SadisSubscriber `json:"entries"`
+	Sadis   *struct {
+		Entries []*SadisSubscriber `json:"entries"`
+	} `json:"sadis"`
+}
+
+// sadisSubscriberCache indexes the subscribers by UNI port name and by ONU
+// serial number
+type sadisSubscriberCache struct {
+	sync.RWMutex
+	byPort   map[string]*SadisSubscriber
+	bySerial map[string][]*SadisSubscriber
+}
+
+// sadisSubscribers is nil unless the SADIS join is enabled in the configuration
+var sadisSubscribers *sadisSubscriberCache
+
+func newSadisSubscriberCache() *sadisSubscriberCache {
+	return &sadisSubscriberCache{
+		byPort:   make(map[string]*SadisSubscriber),
+		bySerial: make(map[string][]*SadisSubscriber),
+	}
+}
+
+// set replaces the subscribers, the entries that are not about an ONU UNI
+// port (OLT entries for example) are left out
+func (c *sadisSubscriberCache) set(subscribers []*SadisSubscriber) int {
+	byPort := make(map[string]*SadisSubscriber)
+	bySerial := make(map[string][]*SadisSubscriber)
+	for _, sub := range subscribers {
+		portName := sub.ID
+		serial := onuSerialFromPortName(portName)
+		if serial == "" {
+			portName = sub.NasPortID
+			serial = onuSerialFromPortName(portName)
+		}
+		if serial == "" {
+			continue
+		}
+		serial = utils.GetOnuSN(serial)
+		byPort[portName] = sub
+		bySerial[serial] = append(bySerial[serial], sub)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	c.byPort = byPort
+	c.bySerial = bySerial
+	return len(byPort)
+}
+
+// lookup returns the subscriber of the UNI port, or of the ONU when the
+// port is unknown and the ONU has a single subscriber
+func (c *sadisSubscriberCache) lookup(serial string, portName string) *SadisSubscriber {
+	if c == nil {
+		return nil
+	}
+	c.RLock()
+	defer c.RUnlock()
+
+	if sub, ok := c.byPort[portName]; ok {
+		return sub
+	}
+	if subs := c.bySerial[serial]; len(subs) == 1 {
+		return subs[0]
+	}
+	return nil
+}
+
+// loadSadisSubscribers reads the SADIS database from a file or an HTTP URL
+func loadSadisSubscribers(source string) ([]*SadisSubscriber, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		client := http.Client{Timeout: sadisHTTPTimeout}
+		resp, err := client.Get(source)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+		}
+		data, err = ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		data, err = ioutil.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	db := sadisDatabase{}
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, err
+	}
+	if db.Sadis != nil {
+		return append(db.Entries, db.Sadis.Entries...), nil
+	}
+	return db.Entries, nil
+}
+
+// refreshSadisSubscribers reloads the SADIS database periodically, the
+// previous subscribers are kept when it cannot be loaded
+func refreshSadisSubscribers(conf SadisInfo) {
+	if conf.Refresh == 0 {
+		conf.Refresh = cDefaultSadisRefresh
+	}
+	ticker := time.NewTicker(conf.Refresh)
+	defer ticker.Stop()
+
+	for {
+		subscribers, err := loadSadisSubscribers(conf.Url)
+		if err != nil {
+			logger.Error("Cannot load the SADIS subscribers from [%s]: %s", conf.Url, err.Error())
+		} else {
+			logger.Debug("Loaded [%d] SADIS subscribers from [%s]", sadisSubscribers.set(subscribers), conf.Url)
+		}
+		<-ticker.C
+	}
+}
+
+// labels added to the metrics of a known subscriber
+const (
+	sadisSubscriberIDLabel = "subscriber_id"
+	sadisCircuitIDLabel    = "circuit_id"
+	sadisRemoteIDLabel     = "remote_id"
+)
+
+// subscriberGatherer adds the subscriber labels to the ONU, UNI and BNG
+// series it can tie to a single SADIS subscriber. Only existing series are
+// labelled, so the join does not add any series.
+type subscriberGatherer struct {
+	prometheus.Gatherer
+}
+
+// Gather implements prometheus.Gatherer
+func (g *subscriberGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			labels := make(map[string]string, len(m.GetLabel()))
+			for _, pair := range m.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+			if _, ok := labels[sadisSubscriberIDLabel]; ok {
+				continue
+			}
+			sub := sadisSubscribers.lookup(subscriberSeriesIdentity(labels))
+			if sub == nil {
+				continue
+			}
+			m.Label = append(m.Label,
+				labelPair(sadisSubscriberIDLabel, sub.ID),
+				labelPair(sadisCircuitIDLabel, sub.CircuitID),
+				labelPair(sadisRemoteIDLabel, sub.RemoteID),
+			)
+			sort.Slice(m.Label, func(i, j int) bool {
+				return m.Label[i].GetName() < m.Label[j].GetName()
+			})
+		}
+	}
+	return mfs, err
+}
+
+// subscriberSeriesIdentity returns the ONU serial number and, when it can
+// be told, the UNI port name a series is about
+func subscriberSeriesIdentity(labels map[string]string) (string, string) {
+	serial, ok := labels["serial_number"]
+	if !ok {
+		serial = labels["onu_serial"]
+	}
+	deviceID := labels["device_id"]
+	portNumber := labels["port_number"]
+
+	// the BNG counters only carry the session identity
+	if mac, ok := labels["mac_address"]; ok && serial == "" {
+		session := &bngSession{Mac: mac, SessionID: labels["session_id"], STag: labels["s_tag"], CTag: labels["c_tag"]}
+		if stored, ok := bngSessions.get(session.key()); ok {
+			serial, deviceID, portNumber = stored.OnuSerial, stored.DeviceID, stored.PortNumber
+		}
+	}
+	if serial == "" {
+		return "", ""
+	}
+
+	// ONOS device and port, or VOLTHA UNI port number which ONOS uses too
+	if info, ok := onosPorts.get(deviceID, portNumber); ok && info.OnuSerial == serial {
+		return serial, info.Name
+	}
+	if info, ok := onosPorts.find(serial, portNumber); ok {
+		return serial, info.Name
+	}
+	return serial, ""
+}
+
+func labelPair(name string, value string) *dto.LabelPair {
+	return &dto.LabelPair{Name: &name, Value: &value}
+}
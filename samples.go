@@ -0,0 +1,93 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// sample is a single value of a gathered family, the summaries and
+// histograms being flattened the way Prometheus stores them
+type sample struct {
+	name   string
+	labels []*dto.LabelPair
+	value  float64
+	// milliseconds since the epoch
+	timestamp int64
+}
+
+// familySamples flattens a gathered family, the series without timestamp
+// get the given one
+func familySamples(mf *dto.MetricFamily, timestamp int64) []*sample {
+	var samples []*sample
+	for _, m := range mf.GetMetric() {
+		ts := timestamp
+		if m.TimestampMs != nil {
+			ts = m.GetTimestampMs()
+		}
+		add := func(suffix string, value float64, extra ...*dto.LabelPair) {
+			labels := append(append([]*dto.LabelPair{}, m.GetLabel()...), extra...)
+			sort.Slice(labels, func(i, j int) bool {
+				return labels[i].GetName() < labels[j].GetName()
+			})
+			samples = append(samples, &sample{
+				name:      mf.GetName() + suffix,
+				labels:    labels,
+				value:     value,
+				timestamp: ts,
+			})
+		}
+
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			add("", m.GetCounter().GetValue())
+		case dto.MetricType_GAUGE:
+			add("", m.GetGauge().GetValue())
+		case dto.MetricType_UNTYPED:
+			add("", m.GetUntyped().GetValue())
+		case dto.MetricType_SUMMARY:
+			for _, q := range m.GetSummary().GetQuantile() {
+				add("", q.GetValue(), labelPair("quantile", formatFloat(q.GetQuantile())))
+			}
+			add("_sum", m.GetSummary().GetSampleSum())
+			add("_count", float64(m.GetSummary().GetSampleCount()))
+		case dto.MetricType_HISTOGRAM:
+			infSeen := false
+			for _, b := range m.GetHistogram().GetBucket() {
+				if math.IsInf(b.GetUpperBound(), +1) {
+					infSeen = true
+				}
+				add("_bucket", float64(b.GetCumulativeCount()), labelPair("le", formatFloat(b.GetUpperBound())))
+			}
+			if !infSeen {
+				add("_bucket", float64(m.GetHistogram().GetSampleCount()), labelPair("le", "+Inf"))
+			}
+			add("_sum", m.GetHistogram().GetSampleSum())
+			add("_count", float64(m.GetHistogram().GetSampleCount()))
+		}
+	}
+	return samples
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, +1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
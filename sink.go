@@ -0,0 +1,404 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// SampleKind is the kind of the metric a sample belongs to
+type SampleKind int
+
+const (
+	GaugeSample SampleKind = iota
+	CounterSample
+	// the value is an observation of a distribution
+	HistogramSample
+)
+
+func (k SampleKind) String() string {
+	switch k {
+	case CounterSample:
+		return "counter"
+	case HistogramSample:
+		return "histogram"
+	}
+	return "gauge"
+}
+
+// Sample is a value decoded by a topic handler, independent of the backend
+type Sample struct {
+	Name        string
+	Kind        SampleKind
+	LabelNames  []string
+	LabelValues []string
+	Value       float64
+	// the value is an increment of the current one rather than the value
+	Delta bool
+	// the time the device reported the value at if known, the time the
+	// sample was written at otherwise
+	Timestamp time.Time
+	// the topic the sample was decoded from
+	Source string
+	// the KPI group the metric is reported in, e.g. onos_port or the
+	// VOLTHA title, empty for the families outside the groups
+	Group string
+}
+
+// Labels returns the labels of the sample by name
+func (s *Sample) Labels() map[string]string {
+	labels := make(map[string]string, len(s.LabelNames))
+	for i, name := range s.LabelNames {
+		labels[name] = s.LabelValues[i]
+	}
+	return labels
+}
+
+// Sink receives the samples of the topic handlers
+type Sink interface {
+	Write(s *Sample)
+	// Delete removes a series, e.g. once a session or a port is gone
+	Delete(name string, labelValues []string)
+}
+
+// metricSinks are the sinks every sample is fanned out to
+var metricSinks = []Sink{prometheusSink{}}
+
+// metricVec is a metric family the topic handlers write to. It hands the
+// samples to the sinks, the Prometheus form of the family being kept for
+// the prometheusSink and the registration.
+type metricVec struct {
+	name   string
+	kind   SampleKind
+	labels []string
+	source string
+	group  string
+	prom   prometheus.Collector
+}
+
+// metricSourceTopics gives the topic the families are decoded from by name
+// prefix, the first matching prefix winning
+var metricSourceTopics = []struct{ prefix, topic string }{
+	{"voltha_", volthaEventsTopic},
+	{"onos_aaa_subscriber_", "authentication.events"},
+	{"onosaaa_", "onos.aaa.stats.kpis"},
+	{"onos_dhcp_", "onos.dhcp.stats.kpis"},
+	{"onos_igmp_", "onos.igmp.stats.kpis"},
+	{"onos_mcast_", "onos.mcast.stats.kpis"},
+	{"onos_port_", "onos.events"},
+	{"onos_", "onos.kpis"},
+	{"bng_", "bng.stats"},
+	{"olt_device_", "dm.metrics"},
+	{"device_", "importer"},
+}
+
+func metricSourceTopic(name string) string {
+	for _, source := range metricSourceTopics {
+		if strings.HasPrefix(name, source.prefix) {
+			return source.topic
+		}
+	}
+	return ""
+}
+
+// metricGroups gives the KPI group of the families by name prefix. The
+// VOLTHA families labelled with the KpiEvent2 title are grouped by title,
+// e.g. voltha_ethernet_uni_history.
+var metricGroups = []struct{ prefix, group string }{
+	{"voltha_onu_uni_", "voltha_uni_status"},
+	{"onos_tx_", "onos_port"},
+	{"onos_rx_", "onos_port"},
+	{"onosaaa_", "onos_aaa"},
+	{"onos_dhcp_", "onos_dhcp"},
+	{"onos_igmp_", "onos_igmp"},
+	{"onos_mcast_", "onos_mcast"},
+	{"bng_up_", "bng"},
+	{"bng_down_", "bng"},
+	{"bng_control_", "bng"},
+	{"olt_device_", "dmi"},
+	{"device_", "importer"},
+}
+
+func metricGroup(name string) string {
+	for _, group := range metricGroups {
+		if strings.HasPrefix(name, group.prefix) {
+			return group.group
+		}
+	}
+	return ""
+}
+
+// metricFamilies holds the families by name, for the prometheusSink
+var metricFamilies = struct {
+	sync.RWMutex
+	vecs map[string]*metricVec
+}{vecs: make(map[string]*metricVec)}
+
+// addMetricFamily keeps the first family of a name, a duplicate fails to
+// register anyway
+func addMetricFamily(vec *metricVec) *metricVec {
+	metricFamilies.Lock()
+	defer metricFamilies.Unlock()
+
+	vec.source = metricSourceTopic(vec.name)
+	vec.group = metricGroup(vec.name)
+	if _, ok := metricFamilies.vecs[vec.name]; !ok {
+		metricFamilies.vecs[vec.name] = vec
+	}
+	return vec
+}
+
+func newGaugeVec(opts prometheus.GaugeOpts, labels []string) *metricVec {
+	return addMetricFamily(&metricVec{
+		name:   prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+		kind:   GaugeSample,
+		labels: labels,
+		prom:   prometheus.NewGaugeVec(opts, labels),
+	})
+}
+
+func newCounterVec(opts prometheus.CounterOpts, labels []string) *metricVec {
+	return addMetricFamily(&metricVec{
+		name:   prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+		kind:   CounterSample,
+		labels: labels,
+		prom:   prometheus.NewCounterVec(opts, labels),
+	})
+}
+
+func newHistogramVec(opts prometheus.HistogramOpts, labels []string) *metricVec {
+	return addMetricFamily(&metricVec{
+		name:   prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+		kind:   HistogramSample,
+		labels: labels,
+		prom:   prometheus.NewHistogramVec(opts, labels),
+	})
+}
+
+// Describe and Collect register the Prometheus form of the family
+func (v *metricVec) Describe(ch chan<- *prometheus.Desc) { v.prom.Describe(ch) }
+func (v *metricVec) Collect(ch chan<- prometheus.Metric) { v.prom.Collect(ch) }
+
+// WithLabelValues returns the series of the family with these label values
+func (v *metricVec) WithLabelValues(values ...string) metricSeries {
+	return metricSeries{vec: v, values: values}
+}
+
+// DeleteLabelValues removes the series from every sink
+func (v *metricVec) DeleteLabelValues(values ...string) {
+	for _, sink := range metricSinks {
+		sink.Delete(v.name, values)
+	}
+}
+
+// sampleClock stamps the samples no device time is known for, a replay
+// sets it to the time the message was recorded at
+var sampleClock = time.Now
+
+// metricSeries writes the samples of a series
+type metricSeries struct {
+	vec    *metricVec
+	values []string
+}
+
+func (s metricSeries) write(value float64, delta bool) {
+	ts, ok := sourceTimestamps.deviceTime(s.vec.labels, s.values)
+	if !ok {
+		ts = sampleClock()
+	}
+	sample := &Sample{
+		Name:        s.vec.name,
+		Kind:        s.vec.kind,
+		LabelNames:  s.vec.labels,
+		LabelValues: s.values,
+		Value:       value,
+		Delta:       delta,
+		Timestamp:   ts,
+		Source:      s.vec.source,
+		Group:       s.vec.group,
+	}
+	if sample.Group == "" {
+		for i, name := range s.vec.labels {
+			if name == "title" && i < len(s.values) && s.values[i] != "" {
+				sample.Group = "voltha_" + sanitizeMetricName(s.values[i])
+			}
+		}
+	}
+	for _, sink := range metricSinks {
+		sink.Write(sample)
+	}
+}
+
+func (s metricSeries) Set(value float64)     { s.write(value, false) }
+func (s metricSeries) Add(value float64)     { s.write(value, true) }
+func (s metricSeries) Inc()                  { s.write(1, true) }
+func (s metricSeries) Dec()                  { s.write(-1, true) }
+func (s metricSeries) Observe(value float64) { s.write(value, false) }
+
+// sampleValue returns the value of the series after the sample, the
+// current value of the Prometheus family for the increments. The
+// prometheusSink is the first sink, the increment is already applied.
+func sampleValue(s *Sample) float64 {
+	if !s.Delta || s.Kind == HistogramSample {
+		return s.Value
+	}
+	metricFamilies.RLock()
+	vec, ok := metricFamilies.vecs[s.Name]
+	metricFamilies.RUnlock()
+	if !ok {
+		return s.Value
+	}
+
+	var metric prometheus.Metric
+	var err error
+	switch prom := vec.prom.(type) {
+	case *prometheus.GaugeVec:
+		metric, err = prom.GetMetricWithLabelValues(s.LabelValues...)
+	case *prometheus.CounterVec:
+		metric, err = prom.GetMetricWithLabelValues(s.LabelValues...)
+	default:
+		return s.Value
+	}
+	m := &dto.Metric{}
+	if err != nil || metric.Write(m) != nil {
+		return s.Value
+	}
+	if m.Counter != nil {
+		return m.GetCounter().GetValue()
+	}
+	return m.GetGauge().GetValue()
+}
+
+// prometheusSink applies the samples to the Prometheus form of their family,
+// served on /metrics
+type prometheusSink struct{}
+
+func (prometheusSink) Write(s *Sample) {
+	metricFamilies.RLock()
+	vec, ok := metricFamilies.vecs[s.Name]
+	metricFamilies.RUnlock()
+	if !ok {
+		logger.Warn("Sample of unknown metric [%s]", s.Name)
+		return
+	}
+
+	switch prom := vec.prom.(type) {
+	case *prometheus.GaugeVec:
+		gauge, err := prom.GetMetricWithLabelValues(s.LabelValues...)
+		if err != nil {
+			logger.Error("Cannot set [%s]: %s", s.Name, err.Error())
+			return
+		}
+		if s.Delta {
+			gauge.Add(s.Value)
+		} else {
+			gauge.Set(s.Value)
+		}
+	case *prometheus.CounterVec:
+		counter, err := prom.GetMetricWithLabelValues(s.LabelValues...)
+		if err != nil {
+			logger.Error("Cannot add to [%s]: %s", s.Name, err.Error())
+			return
+		}
+		// counters only go up, a decrease would panic
+		if s.Delta && s.Value >= 0 {
+			counter.Add(s.Value)
+		}
+	case *prometheus.HistogramVec:
+		histogram, err := prom.GetMetricWithLabelValues(s.LabelValues...)
+		if err != nil {
+			logger.Error("Cannot observe [%s]: %s", s.Name, err.Error())
+			return
+		}
+		histogram.Observe(s.Value)
+	}
+}
+
+func (prometheusSink) Delete(name string, labelValues []string) {
+	metricFamilies.RLock()
+	vec, ok := metricFamilies.vecs[name]
+	metricFamilies.RUnlock()
+	if !ok {
+		return
+	}
+
+	switch prom := vec.prom.(type) {
+	case *prometheus.GaugeVec:
+		prom.DeleteLabelValues(labelValues...)
+	case *prometheus.CounterVec:
+		prom.DeleteLabelValues(labelValues...)
+	case *prometheus.HistogramVec:
+		prom.DeleteLabelValues(labelValues...)
+	}
+}
+
+// pipelineSink hands a sink the samples named and labelled as on /metrics:
+// the gathererWrappers join the SADIS labels, apply the relabel rules, the
+// namespace and the const labels to the sample as to the gathered series.
+// The samples the relabel rules drop are not written. The increments are
+// resolved to the value of the series, the sink getting the values only.
+type pipelineSink struct {
+	Sink
+}
+
+// Write implements Sink
+func (p pipelineSink) Write(s *Sample) {
+	value := sampleValue(s)
+	m := &dto.Metric{Label: sampleLabelPairs(s.LabelNames, s.LabelValues)}
+	mf := &dto.MetricFamily{Name: &s.Name, Metric: []*dto.Metric{m}}
+	if s.Kind == CounterSample {
+		mf.Type = dto.MetricType_COUNTER.Enum()
+		m.Counter = &dto.Counter{Value: &value}
+	} else {
+		mf.Type = dto.MetricType_GAUGE.Enum()
+		m.Gauge = &dto.Gauge{Value: &value}
+	}
+
+	mfs, err := wrapGatherer(sampleGatherer{mf}).Gather()
+	if err != nil {
+		logger.Error("Cannot relabel [%s]: %s", s.Name, err.Error())
+		return
+	}
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			sample := *s
+			sample.Name = mf.GetName()
+			sample.LabelNames = make([]string, 0, len(m.GetLabel()))
+			sample.LabelValues = make([]string, 0, len(m.GetLabel()))
+			for _, pair := range m.GetLabel() {
+				sample.LabelNames = append(sample.LabelNames, pair.GetName())
+				sample.LabelValues = append(sample.LabelValues, pair.GetValue())
+			}
+			sample.Value = value
+			sample.Delta = false
+			p.Sink.Write(&sample)
+		}
+	}
+}
+
+// sampleGatherer gathers the family of a single sample
+type sampleGatherer struct {
+	mf *dto.MetricFamily
+}
+
+func (g sampleGatherer) Gather() ([]*dto.MetricFamily, error) {
+	return []*dto.MetricFamily{g.mf}, nil
+}
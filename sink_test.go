@@ -0,0 +1,119 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingSink keeps the samples, in place of the Prometheus backend
+type recordingSink struct {
+	samples []*Sample
+	deleted []string
+}
+
+func (r *recordingSink) Write(s *Sample) {
+	r.samples = append(r.samples, s)
+}
+
+func (r *recordingSink) Delete(name string, labelValues []string) {
+	r.deleted = append(r.deleted, name)
+}
+
+func TestSinkFanOut(t *testing.T) {
+	first, second := &recordingSink{}, &recordingSink{}
+	defer func(sinks []Sink) { metricSinks = sinks }(metricSinks)
+	metricSinks = []Sink{first, second}
+
+	exportOnosKPI(OnosKPI{
+		DeviceID: "of:0000000000000001",
+		Ports:    []*OnosPort{{PortID: "16", TxBytes: 1024, RxBytes: 2048}},
+	})
+
+	for _, sink := range []*recordingSink{first, second} {
+		assert.Len(t, sink.samples, 6)
+		sample := sink.samples[0]
+		assert.Equal(t, "onos_tx_bytes_total", sample.Name)
+		assert.Equal(t, GaugeSample, sample.Kind)
+		assert.Equal(t, map[string]string{"device_id": "of:0000000000000001", "port_id": "16"}, sample.Labels())
+		assert.Equal(t, 1024.0, sample.Value)
+		assert.False(t, sample.Delta)
+	}
+
+	onosPortEnabled.DeleteLabelValues("of:0000000000000001", "16")
+	assert.Equal(t, []string{"onos_port_enabled"}, first.deleted)
+}
+
+func TestPrometheusSinkCounter(t *testing.T) {
+	vec := newCounterVec(prometheus.CounterOpts{Name: "kte_test_sink_total", Help: "test"}, []string{"device_id"})
+	defer func() {
+		metricFamilies.Lock()
+		delete(metricFamilies.vecs, "kte_test_sink_total")
+		metricFamilies.Unlock()
+	}()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(vec)
+
+	vec.WithLabelValues("olt").Add(3)
+	// counters do not go down, the decrease is ignored
+	vec.WithLabelValues("olt").Add(-1)
+	vec.WithLabelValues("olt").Inc()
+
+	mfs, err := registry.Gather()
+	assert.NoError(t, err)
+	assert.Len(t, mfs, 1)
+	assert.Equal(t, 4.0, mfs[0].GetMetric()[0].GetCounter().GetValue())
+
+	vec.DeleteLabelValues("olt")
+	mfs, err = registry.Gather()
+	assert.NoError(t, err)
+	assert.Empty(t, mfs)
+}
+
+func TestPipelineSink(t *testing.T) {
+	rules, err := compileRelabelRules([]RelabelRule{
+		{SourceLabels: []string{"port_id"}, Regex: "1", Action: relabelDrop},
+		{SourceLabels: []string{"device_id"}, TargetLabel: "olt"},
+		{Regex: "device_id", Action: relabelLabelDrop},
+	})
+	assert.NoError(t, err)
+	namespaced, err := newNamespaceGatherer(metricsRegistry, TargetInfo{Namespace: "lab", ConstLabels: map[string]string{"site": "lab1"}})
+	assert.NoError(t, err)
+	defer func(sinks []Sink, wrappers []func(prometheus.Gatherer) prometheus.Gatherer) {
+		metricSinks, gathererWrappers = sinks, wrappers
+	}(metricSinks, gathererWrappers)
+	recorder := &recordingSink{}
+	metricSinks = []Sink{prometheusSink{}, pipelineSink{recorder}}
+	gathererWrappers = []func(prometheus.Gatherer) prometheus.Gatherer{
+		func(g prometheus.Gatherer) prometheus.Gatherer { return &relabelGatherer{Gatherer: g, rules: rules} },
+		namespaced.wrap,
+	}
+
+	exportOnosKPI(OnosKPI{
+		DeviceID: "of:0000000000000001",
+		Ports:    []*OnosPort{{PortID: "1", TxBytes: 512}, {PortID: "16", TxBytes: 1024}},
+	})
+
+	// the samples of port 1 are dropped, those of port 16 relabelled as on
+	// /metrics
+	assert.Len(t, recorder.samples, 6)
+	sample := recorder.samples[0]
+	assert.Equal(t, "lab_onos_tx_bytes_total", sample.Name)
+	assert.Equal(t, map[string]string{"olt": "of:0000000000000001", "port_id": "16", "site": "lab1"}, sample.Labels())
+	assert.Equal(t, 1024.0, sample.Value)
+}
@@ -0,0 +1,219 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// devices not reporting for this long are forgotten, together with the
+// timestamps of their series
+const (
+	sourceTimestampRetention     = time.Hour
+	sourceTimestampSweepInterval = 5 * time.Minute
+)
+
+// labels identifying the device a series is about: the VOLTHA or ONOS
+// device id, the device manager device uuid and the MAC address of the BNG
+// subscriber
+var sourceTimestampLabels = []string{"device_id", "deviceuuid", "mac_address"}
+
+type sourceTimestamp struct {
+	deviceID string
+	ts       time.Time
+}
+
+// sourceTimestampCache keeps the time each series was last reported at, as
+// stamped by the source, for the outputs that carry timestamps. The
+// handlers observe the time of the report of a device, which the samples
+// then written for the device are stamped with.
+type sourceTimestampCache struct {
+	sync.Mutex
+	devices map[string]time.Time
+	series  map[string]sourceTimestamp
+}
+
+// sourceTimestamps is nil unless an output carrying timestamps is enabled
+var sourceTimestamps *sourceTimestampCache
+
+func newSourceTimestampCache() *sourceTimestampCache {
+	return &sourceTimestampCache{
+		devices: make(map[string]time.Time),
+		series:  make(map[string]sourceTimestamp),
+	}
+}
+
+func (c *sourceTimestampCache) observe(deviceID string, ts time.Time) {
+	if c == nil || deviceID == "" || ts.IsZero() {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+
+	c.devices[deviceID] = ts
+}
+
+// deviceTime returns the time the device of a series last reported at
+func (c *sourceTimestampCache) deviceTime(names []string, values []string) (time.Time, bool) {
+	if c == nil {
+		return time.Time{}, false
+	}
+	c.Lock()
+	defer c.Unlock()
+
+	for i, name := range names {
+		if i < len(values) && containsString(sourceTimestampLabels, name) {
+			if ts, ok := c.devices[values[i]]; ok {
+				return ts, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// Write implements Sink, stamping the series with the time of the report
+// of its device
+func (c *sourceTimestampCache) Write(s *Sample) {
+	labels := sampleLabelPairs(s.LabelNames, s.LabelValues)
+	c.Lock()
+	defer c.Unlock()
+
+	for _, pair := range labels {
+		if !containsString(sourceTimestampLabels, pair.GetName()) {
+			continue
+		}
+		if ts, ok := c.devices[pair.GetValue()]; ok {
+			c.series[s.Name+labelsSignature(labels)] = sourceTimestamp{deviceID: pair.GetValue(), ts: ts}
+			return
+		}
+	}
+}
+
+// Delete implements Sink
+func (c *sourceTimestampCache) Delete(name string, labelValues []string) {
+	metricFamilies.RLock()
+	vec, ok := metricFamilies.vecs[name]
+	metricFamilies.RUnlock()
+	if !ok {
+		return
+	}
+	labels := sampleLabelPairs(vec.labels, labelValues)
+	c.Lock()
+	defer c.Unlock()
+
+	delete(c.series, name+labelsSignature(labels))
+}
+
+// get returns the source timestamp of a series, if known
+func (c *sourceTimestampCache) get(name string, labels []*dto.LabelPair) (time.Time, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	stamp, ok := c.series[name+labelsSignature(labels)]
+	return stamp.ts, ok
+}
+
+// expire forgets the devices not reported since the given time and the
+// series of these devices
+func (c *sourceTimestampCache) expire(before time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	for deviceID, ts := range c.devices {
+		if ts.Before(before) {
+			delete(c.devices, deviceID)
+		}
+	}
+	for key, stamp := range c.series {
+		if _, ok := c.devices[stamp.deviceID]; !ok {
+			delete(c.series, key)
+		}
+	}
+}
+
+func (c *sourceTimestampCache) run() {
+	ticker := time.NewTicker(sourceTimestampSweepInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		c.expire(time.Now().Add(-sourceTimestampRetention))
+	}
+}
+
+// sampleLabelPairs returns the labels of a sample sorted by name, as
+// gathered
+func sampleLabelPairs(names []string, values []string) []*dto.LabelPair {
+	labels := make([]*dto.LabelPair, 0, len(names))
+	for i, name := range names {
+		if i < len(values) {
+			labels = append(labels, labelPair(name, values[i]))
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].GetName() < labels[j].GetName() })
+	return labels
+}
+
+// sourceTimestampGatherer stamps the gathered series with their source
+// timestamp. It wraps the registry, before the labels are rewritten, for
+// the outputs that carry timestamps, /metrics leaving the timestamps out.
+type sourceTimestampGatherer struct {
+	prometheus.Gatherer
+	cache *sourceTimestampCache
+}
+
+func (g *sourceTimestampGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			if m.TimestampMs != nil {
+				continue
+			}
+			if ts, ok := g.cache.get(mf.GetName(), m.GetLabel()); ok {
+				m.TimestampMs = proto.Int64(ts.UnixNano() / int64(time.Millisecond))
+			}
+		}
+	}
+	return mfs, err
+}
+
+// onosTimestamp is the time of an ONOS message, an RFC 3339 string or
+// milliseconds since the epoch. A time that cannot be parsed is left zero
+// rather than failing the message.
+type onosTimestamp time.Time
+
+func (t *onosTimestamp) UnmarshalJSON(data []byte) error {
+	var timestamp string
+	if err := json.Unmarshal(data, &timestamp); err != nil {
+		timestamp = string(data)
+	}
+	if ts, ok := parseBngTimestamp(timestamp); ok {
+		*t = onosTimestamp(ts)
+	}
+	return nil
+}
+
+// volthaTimestamp converts a VOLTHA timestamp, in seconds since the epoch
+func volthaTimestamp(ts float64) time.Time {
+	if ts <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(ts*float64(time.Second)))
+}
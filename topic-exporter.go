@@ -15,9 +15,12 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
 	"gerrit.opencord.org/kafka-topic-exporter/utils"
@@ -30,28 +33,28 @@ import (
 
 var (
 	// voltha kpis
-	volthaOltTxBytesTotal = prometheus.NewGaugeVec(
+	volthaOltTxBytesTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_olt_tx_bytes_total",
 			Help: "Number of total bytes transmitted",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
-	volthaOltRxBytesTotal = prometheus.NewGaugeVec(
+	volthaOltRxBytesTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_olt_rx_bytes_total",
 			Help: "Number of total bytes received",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
-	volthaOltTxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOltTxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_olt_tx_packets_total",
 			Help: "Number of total packets transmitted",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
-	volthaOltRxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOltRxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_olt_rx_packets_total",
 			Help: "Number of total packets received",
@@ -59,7 +62,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
 
-	volthaOltTxErrorPacketsTotal = prometheus.NewGaugeVec(
+	volthaOltTxErrorPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_olt_tx_error_packets_total",
 			Help: "Number of total transmitted packets error",
@@ -67,7 +70,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
 
-	volthaOltRxErrorPacketsTotal = prometheus.NewGaugeVec(
+	volthaOltRxErrorPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_olt_rx_error_packets_total",
 			Help: "Number of total received packets error",
@@ -75,7 +78,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
 
-	volthaOltTxBroadcastPacketsTotal = prometheus.NewGaugeVec(
+	volthaOltTxBroadcastPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_olt_tx_broadcast_packets_total",
 			Help: "Number of total broadcast packets transmitted",
@@ -83,7 +86,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
 
-	volthaOltTxUnicastPacketsTotal = prometheus.NewGaugeVec(
+	volthaOltTxUnicastPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_olt_tx_unicast_packets_total",
 			Help: "Number of total unicast packets transmitted",
@@ -91,7 +94,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
 
-	volthaOltTxMulticastPacketsTotal = prometheus.NewGaugeVec(
+	volthaOltTxMulticastPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_olt_tx_multicast_packets_total",
 			Help: "Number of total multicast packets transmitted",
@@ -99,7 +102,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
 
-	volthaOltRxBroadcastPacketsTotal = prometheus.NewGaugeVec(
+	volthaOltRxBroadcastPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_olt_rx_broadcast_packets_total",
 			Help: "Number of total broadcast packets received",
@@ -107,7 +110,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
 
-	volthaOltRxUnicastPacketsTotal = prometheus.NewGaugeVec(
+	volthaOltRxUnicastPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_olt_rx_unicast_packets_total",
 			Help: "Number of total unicast packets received",
@@ -115,7 +118,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
 
-	volthaOltRxMulticastPacketsTotal = prometheus.NewGaugeVec(
+	volthaOltRxMulticastPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_olt_rx_multicast_packets_total",
 			Help: "Number of total multicast packets received",
@@ -124,39 +127,39 @@ var (
 	)
 
 	// optical parameters
-	VolthaOnuLaserBiasCurrent = prometheus.NewGaugeVec(
+	VolthaOnuLaserBiasCurrent = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_laser_bias_current",
-			Help: "ONU Laser bias current value",
+			Help: "ONU Laser bias current value in mA",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
 
-	volthaOnuTemperature = prometheus.NewGaugeVec(
+	volthaOnuTemperature = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_temperature",
-			Help: "ONU temperature value",
+			Help: "ONU temperature value in degrees Celsius",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
 
-	VolthaOnuPowerFeedVoltage = prometheus.NewGaugeVec(
+	VolthaOnuPowerFeedVoltage = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_power_feed_voltage",
-			Help: "ONU power feed voltage",
+			Help: "ONU power feed voltage in Volts",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
 
-	VolthaOnuMeanOpticalLaunchPower = prometheus.NewGaugeVec(
+	VolthaOnuMeanOpticalLaunchPower = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_mean_optical_launch_power",
-			Help: "ONU mean optical launch power",
+			Help: "ONU mean optical launch power in dBm",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
 
-	VolthaOnuReceivedOpticalPower = prometheus.NewGaugeVec(
+	VolthaOnuReceivedOpticalPower = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_received_optical_power",
 			Help: "ONU received optical power",
@@ -164,7 +167,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "pon_id", "port_number", "title"},
 	)
 
-	VolthaOnuTransmtOpticalPower = prometheus.NewGaugeVec(
+	VolthaOnuTransmtOpticalPower = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_transmit_optical_power",
 			Help: "ONU transmited optical power",
@@ -173,7 +176,7 @@ var (
 	)
 
 	// FEC parameters
-	volthaOnuFecCorrectedCodewordsTotal = prometheus.NewGaugeVec(
+	volthaOnuFecCorrectedCodewordsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_fec_corrected_code_words",
 			Help: "Number of total code words corrected",
@@ -181,7 +184,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuFecCodewordsTotal = prometheus.NewGaugeVec(
+	volthaOnuFecCodewordsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_fec_code_words_total",
 			Help: "Number of total code words",
@@ -189,7 +192,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuFecCorrectedBytesTotal = prometheus.NewGaugeVec(
+	volthaOnuFecCorrectedBytesTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_fec_corrected_bytes_total",
 			Help: "Number of total corrected bytes",
@@ -197,7 +200,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuFecSecondsTotal = prometheus.NewGaugeVec(
+	volthaOnuFecSecondsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_fec_corrected_fec_seconds_total",
 			Help: "Number of fec seconds total",
@@ -205,7 +208,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuFecUncorrectablewordsTotal = prometheus.NewGaugeVec(
+	volthaOnuFecUncorrectablewordsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_fec_uncorrectable_words_total",
 			Help: "Number of fec uncorrectable words",
@@ -214,7 +217,7 @@ var (
 	)
 	//Etheret UNI
 
-	volthaEthernetUniSingleCollisionTotal = prometheus.NewGaugeVec(
+	volthaEthernetUniSingleCollisionTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_ethernet_uni_single_collision_frame_counter",
 			Help: "successfully transmitted frames but delayed by exactly one collision.",
@@ -222,7 +225,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "port_number", "title"},
 	)
 
-	volthaEthernetUniMacLayerTramsmitErrorTotal = prometheus.NewGaugeVec(
+	volthaEthernetUniMacLayerTramsmitErrorTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_ethernet_uni_internal_mac_rx_error_counter",
 			Help: "transmission failed due to an internal MAC sublayer transmit error.",
@@ -230,7 +233,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "port_number", "title"},
 	)
 
-	volthaEthernetUniMultiCollisionTotal = prometheus.NewGaugeVec(
+	volthaEthernetUniMultiCollisionTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_ethernet_uni_multiple_collisions_frame_counter",
 			Help: "successfully transmitted frames but delayed by multiple collisions.",
@@ -238,7 +241,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "port_number", "title"},
 	)
 
-	volthaEthernetUniFramestooLongTotal = prometheus.NewGaugeVec(
+	volthaEthernetUniFramestooLongTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_ethernet_uni_frames_too_long",
 			Help: "frames that exceeded the maximum permitted frame size.",
@@ -246,93 +249,239 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "port_number", "title"},
 	)
 
-	volthaEthernetUniAlignmentErrorTotal = prometheus.NewGaugeVec(
+	volthaEthernetUniAlignmentErrorTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_ethernet_uni_alignment_error_counter",
 			Help: "frames that were not an integral number of octets in length and did not pass the FCS check.",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "port_number", "title"},
 	)
-	volthaEthernetUniCarrierErrorTotal = prometheus.NewGaugeVec(
+	volthaEthernetUniCarrierErrorTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_ethernet_uni_carrier_sense_error_counter",
 			Help: "number of times that carrier sense was lost or never asserted when attempting to transmit a frame.",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "port_number", "title"},
 	)
-	volthaEthernetUniExcessiveCollisionErrorTotal = prometheus.NewGaugeVec(
+	volthaEthernetUniExcessiveCollisionErrorTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_ethernet_uni_excessive_collision_counter",
 			Help: "frames whose transmission failed due to excessive collisions.",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "port_number", "title"},
 	)
-	volthaEthernetUniDeferredTxTotal = prometheus.NewGaugeVec(
+	volthaEthernetUniDeferredTxTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_ethernet_uni_deferred_tx_counter",
 			Help: "frames whose first transmission attempt was delayed because the medium was busy.",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "port_number", "title"},
 	)
-	volthaEthernetUniLateCollisionTotal = prometheus.NewGaugeVec(
+	volthaEthernetUniLateCollisionTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_ethernet_uni_late_collision_counter",
 			Help: "number of times that a collision was detected later than 512 bit times into the transmission of a packet.",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "port_number", "title"},
 	)
-	volthaEthernetUniBufferOverflowsRxErrorTotal = prometheus.NewGaugeVec(
+	volthaEthernetUniBufferOverflowsRxErrorTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_ethernet_uni_buffer_overflows_on_rx",
 			Help: "number of times that the receive buffer overflowed.",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "port_number", "title"},
 	)
-	volthaEthernetUniFcsErrorTotal = prometheus.NewGaugeVec(
+	volthaEthernetUniFcsErrorTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_ethernet_uni_fcs_errors",
 			Help: " frames failed the frame check sequence (FCS) check.",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "port_number", "title"},
 	)
-	volthaEthernetUniSqeErrorTotal = prometheus.NewGaugeVec(
+	volthaEthernetUniSqeErrorTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_ethernet_uni_sqe_counter",
 			Help: "number of times that the SQE test error message was generated by the PLS sublayer",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "port_number", "title"},
 	)
-	volthaEthernetUniBufferOverflowsTxErrorTotal = prometheus.NewGaugeVec(
+	volthaEthernetUniBufferOverflowsTxErrorTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_ethernet_uni_buffer_overflows_on_tx",
 			Help: " number of times that the transmit buffer overflowed.",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "interface_id", "port_number", "title"},
 	)
+	//UNI Status
+	volthaOnuUniOperState = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_uni_oper_state",
+			Help: "UNI operational state (1 enabled, 0 disabled)",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "me_instance"},
+	)
+	volthaOnuUniAdminState = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_uni_admin_state",
+			Help: "UNI administrative state (1 unlocked, 0 locked)",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "me_instance"},
+	)
+	volthaOnuUniInfo = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_uni_info",
+			Help: "UNI sensed type and configured speed, value is always 1",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "me_instance", "sensed_type", "speed", "duplex"},
+	)
+
+	//Ethernet_Frame_Extended_PM
+	volthaOnuEthFrameExtDropEvents = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_ethernet_frame_extended_drop_events_total",
+			Help: "Number of events in which frames were dropped due to a lack of resources",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "direction", "title"},
+	)
+	volthaOnuEthFrameExtOctets = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_ethernet_frame_extended_octets_total",
+			Help: "Number of octets, including those in bad frames",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "direction", "title"},
+	)
+	volthaOnuEthFrameExtFrames = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_ethernet_frame_extended_frames_total",
+			Help: "Number of frames, including bad frames, broadcast frames and multicast frames",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "direction", "title"},
+	)
+	volthaOnuEthFrameExtBroadcastFrames = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_ethernet_frame_extended_broadcast_frames_total",
+			Help: "Number of good frames directed to the broadcast address",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "direction", "title"},
+	)
+	volthaOnuEthFrameExtMulticastFrames = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_ethernet_frame_extended_multicast_frames_total",
+			Help: "Number of good frames directed to a multicast address",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "direction", "title"},
+	)
+	volthaOnuEthFrameExtCrcErroredFrames = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_ethernet_frame_extended_crc_errored_frames_total",
+			Help: "Number of frames with a length between 64 and 1518 octets that had a bad FCS",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "direction", "title"},
+	)
+	volthaOnuEthFrameExtUndersizeFrames = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_ethernet_frame_extended_undersize_frames_total",
+			Help: "Number of frames that were less than 64 octets long but were otherwise well formed",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "direction", "title"},
+	)
+	volthaOnuEthFrameExtOversizeFrames = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_ethernet_frame_extended_oversize_frames_total",
+			Help: "Number of frames that were longer than 1518 octets and were otherwise well formed",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "direction", "title"},
+	)
+	volthaOnuEthFrameExt64Octet = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_ethernet_frame_extended_frames_64_octets_total",
+			Help: "Number of frames, including bad frames, that were 64 octets long",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "direction", "title"},
+	)
+	volthaOnuEthFrameExt65To127Octet = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_ethernet_frame_extended_frames_65_to_127_octets_total",
+			Help: "Number of frames, including bad frames, that were 65..127 octets long",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "direction", "title"},
+	)
+	volthaOnuEthFrameExt128To255Octet = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_ethernet_frame_extended_frames_128_to_255_octets_total",
+			Help: "Number of frames, including bad frames, that were 128..255 octets long",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "direction", "title"},
+	)
+	volthaOnuEthFrameExt256To511Octet = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_ethernet_frame_extended_frames_256_to_511_octets_total",
+			Help: "Number of frames, including bad frames, that were 256..511 octets long",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "direction", "title"},
+	)
+	volthaOnuEthFrameExt512To1023Octet = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_ethernet_frame_extended_frames_512_to_1023_octets_total",
+			Help: "Number of frames, including bad frames, that were 512..1023 octets long",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "direction", "title"},
+	)
+	volthaOnuEthFrameExt1024To1518Octet = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_ethernet_frame_extended_frames_1024_to_1518_octets_total",
+			Help: "Number of frames, including bad frames, that were 1024..1518 octets long",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "port_number", "direction", "title"},
+	)
+
+	//GEM_Port_History
+	volthaOnuGemPortFrames = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_gem_port_frames_total",
+			Help: "Number of GEM frames",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "gem_port_id", "direction", "title"},
+	)
+	volthaOnuGemPortPayloadBytes = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_gem_port_payload_bytes_total",
+			Help: "Number of GEM payload bytes",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "gem_port_id", "direction", "title"},
+	)
+	volthaOnuGemPortEncryptionKeyErrors = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "voltha_onu_gem_port_encryption_key_errors_total",
+			Help: "Number of GEM frames received with an unknown or invalid encryption key",
+		},
+		[]string{"logical_device_id", "serial_number", "device_id", "gem_port_id", "direction", "title"},
+	)
+
 	//Ethernet_Bridge_Port
 
-	volthaOnuBridgePortTxBytesTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortTxBytesTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_tx_bytes_total",
 			Help: "Number of total bytes transmitted",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
-	volthaOnuBridgePortRxBytesTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortRxBytesTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_rx_bytes_total",
 			Help: "Number of total bytes received",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
-	volthaOnuBridgePortTxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortTxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_tx_packets_total",
 			Help: "Number of total packets transmitted",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
-	volthaOnuBridgePortRxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortRxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_rx_packets_total",
 			Help: "Number of total packets received",
@@ -340,7 +489,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuBridgePort_64octetTxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePort_64octetTxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_64_octets_Txpackets",
 			Help: "packets (including bad packets) that were 64 octets long",
@@ -348,7 +497,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuBridgePort_65_127_octetTxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePort_65_127_octetTxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_65_to_127_octet_Txpackets",
 			Help: "packets (including bad packets) that were 65..127 octets long, excluding framing bits but including FCS.",
@@ -356,7 +505,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuBridgePort_128_255_octetTxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePort_128_255_octetTxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_128_to_255_octet_Txpackets",
 			Help: "packets (including bad packets) received that were 128..255 octets long, excluding framing bits but including FCS.",
@@ -364,7 +513,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuBridgePort_256_511_octetTxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePort_256_511_octetTxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_256_to_511_octet_Txpackets",
 			Help: "packets (including bad packets) received that were 256..511 octets long, excluding framing bits but including FCS.",
@@ -372,7 +521,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuBridgePort_512_1023_octetTxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePort_512_1023_octetTxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_512_to_1023_octet_Txpackets",
 			Help: "packets (including bad packets) received that were 512..1 023 octets long, excluding framing bits but including FCS.",
@@ -380,42 +529,42 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuBridgePort_1024_1518_octetTxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePort_1024_1518_octetTxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_1024_to_1518_octet_Txpackets",
 			Help: "packets (including bad packets) received that were 1024..1518 octets long, excluding framing bits, but including FCS.",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
-	volthaOnuBridgePortTxMulticastPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortTxMulticastPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_multicast_Txpackets",
 			Help: "packets received that were directed to a multicast address.",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
-	volthaOnuBridgePortTxBroadcastPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortTxBroadcastPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_broadcast_Txpackets",
 			Help: "packets received that were directed to the broadcast address.",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
-	volthaOnuBridgePortTxOversizePacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortTxOversizePacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_oversize_Txpackets",
 			Help: " packets received that were longer than 1518 octets",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
-	volthaOnuBridgePortTxCrcErrorPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortTxCrcErrorPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_crc_errored_Txpackets",
 			Help: "Packets with CRC errors",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
-	volthaOnuBridgePortTxUndersizePacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortTxUndersizePacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_undersize_Txpackets",
 			Help: "Packets received that were less than 64 octets long, but were otherwise well formed",
@@ -423,14 +572,14 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuBridgePortTxDropEventsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortTxDropEventsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_Txdrop_events",
 			Help: "total number of events in which packets were dropped due to a lack of resources. ",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
-	volthaOnuBridgePort_64octetRxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePort_64octetRxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_64_octets_Rxpackets",
 			Help: "packets (including bad packets) that were 64 octets long",
@@ -438,7 +587,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuBridgePort_65_127_octetRxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePort_65_127_octetRxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_65_to_127_octet_Rxpackets",
 			Help: "packets (including bad packets) that were 65..127 octets long, excluding framing bits but including FCS.",
@@ -446,7 +595,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuBridgePort_128_255_octetRxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePort_128_255_octetRxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_128_to_255_octet_packets",
 			Help: "packets (including bad packets) received that were 128..255 octets long, excluding framing bits but including FCS.",
@@ -454,7 +603,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuBridgePort_256_511_octetRxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePort_256_511_octetRxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_256_to_511_octet_Rxpackets",
 			Help: "packets (including bad packets) received that were 256..511 octets long, excluding framing bits but including FCS.",
@@ -462,7 +611,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuBridgePort_512_1023_octetRxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePort_512_1023_octetRxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_512_to_1023_octet_Rxpackets",
 			Help: "packets (including bad packets) received that were 512..1 023 octets long, excluding framing bits but including FCS.",
@@ -470,42 +619,42 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuBridgePort_1024_1518_octetRxPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePort_1024_1518_octetRxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_1024_to_1518_octet_Rxpackets",
 			Help: "packets (including bad packets) received that were 1024..1518 octets long, excluding framing bits, but including FCS.",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
-	volthaOnuBridgePortRxMulticastPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortRxMulticastPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_multicast_Rxpackets",
 			Help: "packets received that were directed to a multicast address.",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
-	volthaOnuBridgePortRxBroadcastPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortRxBroadcastPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_broadcast_Rxpackets",
 			Help: "packets received that were directed to the broadcast address.",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
-	volthaOnuBridgePortRxOversizePacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortRxOversizePacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_oversize_Rxpackets",
 			Help: " packets received that were longer than 1518 octets",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
-	volthaOnuBridgePortRxCrcErrorPacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortRxCrcErrorPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_crc_errored_Rxpackets",
 			Help: "Packets with CRC errors",
 		},
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
-	volthaOnuBridgePortRxUndersizePacketsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortRxUndersizePacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_undersize_Rxpackets",
 			Help: "Packets received that were less than 64 octets long, but were otherwise well formed",
@@ -513,7 +662,7 @@ var (
 		[]string{"logical_device_id", "serial_number", "device_id", "title"},
 	)
 
-	volthaOnuBridgePortRxDropEventsTotal = prometheus.NewGaugeVec(
+	volthaOnuBridgePortRxDropEventsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "voltha_onu_bridge_port_Rxdrop_events",
 			Help: "total number of events in which packets were dropped due to a lack of resources. ",
@@ -522,28 +671,28 @@ var (
 	)
 
 	// onos kpis
-	onosTxBytesTotal = prometheus.NewGaugeVec(
+	onosTxBytesTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onos_tx_bytes_total",
 			Help: "Number of total bytes transmitted",
 		},
 		[]string{"device_id", "port_id"},
 	)
-	onosRxBytesTotal = prometheus.NewGaugeVec(
+	onosRxBytesTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onos_rx_bytes_total",
 			Help: "Number of total bytes received",
 		},
 		[]string{"device_id", "port_id"},
 	)
-	onosTxPacketsTotal = prometheus.NewGaugeVec(
+	onosTxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onos_tx_packets_total",
 			Help: "Number of total packets transmitted",
 		},
 		[]string{"device_id", "port_id"},
 	)
-	onosRxPacketsTotal = prometheus.NewGaugeVec(
+	onosRxPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onos_rx_packets_total",
 			Help: "Number of total packets received",
@@ -551,7 +700,7 @@ var (
 		[]string{"device_id", "port_id"},
 	)
 
-	onosTxDropPacketsTotal = prometheus.NewGaugeVec(
+	onosTxDropPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onos_tx_drop_packets_total",
 			Help: "Number of total transmitted packets dropped",
@@ -559,7 +708,7 @@ var (
 		[]string{"device_id", "port_id"},
 	)
 
-	onosRxDropPacketsTotal = prometheus.NewGaugeVec(
+	onosRxDropPacketsTotal = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onos_rx_drop_packets_total",
 			Help: "Number of total received packets dropped",
@@ -567,195 +716,262 @@ var (
 		[]string{"device_id", "port_id"},
 	)
 
+	// onos.events port metadata
+	onosPortInfo = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_port_info",
+			Help: "ONOS port metadata, value is always 1",
+		},
+		[]string{"device_id", "port_id", "port_name", "port_type", "port_speed", "onu_serial"},
+	)
+
+	onosPortEnabled = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_port_enabled",
+			Help: "ONOS port enabled state (1 enabled, 0 disabled)",
+		},
+		[]string{"device_id", "port_id"},
+	)
+
 	// onos.aaa kpis
-	onosaaaRxAcceptResponses = prometheus.NewGauge(
+	onosaaaRxAcceptResponses = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_rx_accept_responses",
 			Help: "Number of access accept packets received from the server",
-		})
-	onosaaaRxRejectResponses = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaRxRejectResponses = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_rx_reject_responses",
 			Help: "Number of access reject packets received from the server",
-		})
-	onosaaaRxChallengeResponses = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaRxChallengeResponses = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_rx_challenge_response",
 			Help: "Number of access challenge packets received from the server",
-		})
-	onosaaaTxAccessRequests = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaTxAccessRequests = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_tx_access_requests",
 			Help: "Number of access request packets sent to the server",
-		})
-	onosaaaRxInvalidValidators = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaRxInvalidValidators = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_rx_invalid_validators",
 			Help: "Number of access response packets received from the server with an invalid validator",
-		})
-	onosaaaRxUnknownType = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaRxUnknownType = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_rx_unknown_type",
 			Help: "Number of packets of an unknown RADIUS type received from the accounting server",
-		})
-	onosaaaPendingRequests = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaPendingRequests = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_pending_responses",
 			Help: "Number of access request packets pending a response from the server",
-		})
-	onosaaaRxDroppedResponses = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaRxDroppedResponses = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_rx_dropped_responses",
 			Help: "Number of dropped packets received from the accounting server",
-		})
-	onosaaaRxMalformedResponses = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaRxMalformedResponses = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_rx_malformed_responses",
 			Help: "Number of malformed access response packets received from the server",
-		})
-	onosaaaRxUnknownserver = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaRxUnknownserver = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_rx_from_unknown_server",
 			Help: "Number of packets received from an unknown server",
-		})
-	onosaaaRequestRttMillis = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaRequestRttMillis = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_request_rttmillis",
 			Help: "Roundtrip packet time to the accounting server in Miliseconds",
-		})
-	onosaaaRequestReTx = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaRequestReTx = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_request_re_tx",
 			Help: "Number of access request packets retransmitted to the server",
-		})
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
 
 	// ONOS BNG kpis
 
 	// --------------------- BNG UPSTREAM STATISTICS -----------------------------------------
-	onosBngUpTxBytes = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "onosBngUpTxBytes",
-			Help: "onosBngUpTxBytes",
+	onosBngUpTxBytesTotal = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "bng_up_tx_bytes_total",
+			Help: "Number of bytes transmitted upstream",
 		},
-		[]string{"mac_address", "ip", "session_id", "s_tag", "c_tag", "onu_serial", "type"},
+		[]string{"mac_address", "session_id", "s_tag", "c_tag"},
 	)
-	onosBngUpTxPackets = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "onosBngUpTxPackets",
-			Help: "onosBngUpTxPackets",
+	onosBngUpTxPacketsTotal = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "bng_up_tx_packets_total",
+			Help: "Number of packets transmitted upstream",
 		},
-		[]string{"mac_address", "ip", "session_id", "s_tag", "c_tag", "onu_serial", "type"},
+		[]string{"mac_address", "session_id", "s_tag", "c_tag"},
 	)
-
-	onosBngUpRxBytes = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "onosBngUpRxBytes",
-			Help: "onosBngUpRxBytes",
+	onosBngUpRxBytesTotal = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "bng_up_rx_bytes_total",
+			Help: "Number of bytes received upstream",
 		},
-		[]string{"mac_address", "ip", "session_id", "s_tag", "c_tag", "onu_serial", "type"},
+		[]string{"mac_address", "session_id", "s_tag", "c_tag"},
 	)
-
-	onosBngUpRxPackets = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "onosBngUpRxPackets",
-			Help: "onosBngUpRxPackets",
+	onosBngUpRxPacketsTotal = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "bng_up_rx_packets_total",
+			Help: "Number of packets received upstream",
 		},
-		[]string{"mac_address", "ip", "session_id", "s_tag", "c_tag", "onu_serial", "type"},
+		[]string{"mac_address", "session_id", "s_tag", "c_tag"},
 	)
-	onosBngUpDropBytes = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "onosBngUpDropBytes",
-			Help: "onosBngUpDropBytes",
+	onosBngUpDropBytesTotal = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "bng_up_drop_bytes_total",
+			Help: "Number of upstream bytes dropped",
 		},
-		[]string{"mac_address", "ip", "session_id", "s_tag", "c_tag", "onu_serial", "type"},
+		[]string{"mac_address", "session_id", "s_tag", "c_tag"},
 	)
-	onosBngUpDropPackets = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "onosBngUpDropPackets",
-			Help: "onosBngUpDropPackets",
+	onosBngUpDropPacketsTotal = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "bng_up_drop_packets_total",
+			Help: "Number of upstream packets dropped",
 		},
-		[]string{"mac_address", "ip", "session_id", "s_tag", "c_tag", "onu_serial", "type"},
+		[]string{"mac_address", "session_id", "s_tag", "c_tag"},
 	)
 
 	// --------------------- BNG CONTROL STATISTICS ------------------------------------------
-	onosBngControlPackets = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "onosBngControlPackets",
-			Help: "onosBngControlPackets",
+	onosBngControlPacketsTotal = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "bng_control_packets_total",
+			Help: "Number of control packets",
 		},
-		[]string{"mac_address", "ip", "session_id", "s_tag", "c_tag", "onu_serial", "type"},
+		[]string{"mac_address", "session_id", "s_tag", "c_tag"},
 	)
 
 	// -------------------- BNG DOWNSTREAM STATISTICS ----------------------------------------
-	onosBngDownTxBytes = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "onosBngDownTxBytes",
-			Help: "onosBngDownTxBytes",
+	onosBngDownTxBytesTotal = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "bng_down_tx_bytes_total",
+			Help: "Number of bytes transmitted downstream",
 		},
-		[]string{"mac_address", "ip", "session_id", "s_tag", "c_tag", "onu_serial", "type"},
+		[]string{"mac_address", "session_id", "s_tag", "c_tag"},
 	)
-	onosBngDownTxPackets = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "onosBngDownTxPackets",
-			Help: "onosBngDownTxPackets",
+	onosBngDownTxPacketsTotal = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "bng_down_tx_packets_total",
+			Help: "Number of packets transmitted downstream",
 		},
-		[]string{"mac_address", "ip", "session_id", "s_tag", "c_tag", "onu_serial", "type"},
+		[]string{"mac_address", "session_id", "s_tag", "c_tag"},
+	)
+	onosBngDownRxBytesTotal = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "bng_down_rx_bytes_total",
+			Help: "Number of bytes received downstream",
+		},
+		[]string{"mac_address", "session_id", "s_tag", "c_tag"},
+	)
+	onosBngDownRxPacketsTotal = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "bng_down_rx_packets_total",
+			Help: "Number of packets received downstream",
+		},
+		[]string{"mac_address", "session_id", "s_tag", "c_tag"},
+	)
+	onosBngDownDropBytesTotal = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "bng_down_drop_bytes_total",
+			Help: "Number of downstream bytes dropped",
+		},
+		[]string{"mac_address", "session_id", "s_tag", "c_tag"},
+	)
+	onosBngDownDropPacketsTotal = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "bng_down_drop_packets_total",
+			Help: "Number of downstream packets dropped",
+		},
+		[]string{"mac_address", "session_id", "s_tag", "c_tag"},
 	)
 
-	onosBngDownRxBytes = prometheus.NewGaugeVec(
+	// --------------------- BNG SESSIONS ----------------------------------------------------
+	onosBngSessionInfo = newGaugeVec(
 		prometheus.GaugeOpts{
-			Name: "onosBngDownRxBytes",
-			Help: "onosBngDownRxBytes",
+			Name: "bng_session_info",
+			Help: "BNG session information, value is always 1",
 		},
-		[]string{"mac_address", "ip", "session_id", "s_tag", "c_tag", "onu_serial", "type"},
+		[]string{"mac_address", "session_id", "s_tag", "c_tag", "ip", "onu_serial", "type", "device_id", "port_number"},
 	)
-	onosBngDownRxPackets = prometheus.NewGaugeVec(
+	onosBngSessionStartTime = newGaugeVec(
 		prometheus.GaugeOpts{
-			Name: "onosBngDownRxPackets",
-			Help: "onosBngDownRxPackets",
+			Name: "bng_session_start_time_seconds",
+			Help: "Time the BNG session was first reported",
 		},
-		[]string{"mac_address", "ip", "session_id", "s_tag", "c_tag", "onu_serial", "type"},
+		[]string{"mac_address", "session_id", "s_tag", "c_tag"},
 	)
-
-	onosBngDownDropBytes = prometheus.NewGaugeVec(
+	onosBngActiveSessions = newGaugeVec(
 		prometheus.GaugeOpts{
-			Name: "onosBngDownDropBytes",
-			Help: "onosBngDownDropBytes",
+			Name: "bng_active_sessions",
+			Help: "Number of active BNG sessions per attachment type",
 		},
-		[]string{"mac_address", "ip", "session_id", "s_tag", "c_tag", "onu_serial", "type"},
+		[]string{"type"},
 	)
-	onosBngDownDropPackets = prometheus.NewGaugeVec(
+	onosBngActiveSessionsPerSTag = newGaugeVec(
 		prometheus.GaugeOpts{
-			Name: "onosBngDownDropPackets",
-			Help: "onosBngDownDropPackets",
+			Name: "bng_active_sessions_per_s_tag",
+			Help: "Number of active BNG sessions per S-tag",
 		},
-		[]string{"mac_address", "ip", "session_id", "s_tag", "c_tag", "onu_serial", "type"},
+		[]string{"s_tag"},
 	)
 	/* The device metrics will be removed in future and device
 	   metrics defined in VOL-3255 will be supported
 	*/
-	deviceLaserBiasCurrent = prometheus.NewGaugeVec(
+	deviceLaserBiasCurrent = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "device_laser_bias_current",
 			Help: "Device Laser Bias Current",
 		},
 		[]string{"port_id"},
 	)
-	deviceTemperature = prometheus.NewGaugeVec(
+	deviceTemperature = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "device_temperature",
 			Help: "Device Temperature",
 		},
 		[]string{"port_id"},
 	)
-	deviceTxPower = prometheus.NewGaugeVec(
+	deviceTxPower = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "device_tx_power",
 			Help: "Device Tx Power",
 		},
 		[]string{"port_id"},
 	)
-	deviceVoltage = prometheus.NewGaugeVec(
+	deviceVoltage = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "device_voltage",
 			Help: "Device Voltage",
@@ -763,89 +979,262 @@ var (
 		[]string{"port_id"},
 	)
 
-	onosaaaRxEapolLogoff = prometheus.NewGauge(
+	onosaaaRxEapolLogoff = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_rx_eapol_Logoff",
 			Help: "Number of EAPOL logoff messages received resulting in disconnected state",
-		})
-	onosaaaTxEapolResIdentityMsg = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaTxEapolResIdentityMsg = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_tx_eapol_Res_IdentityMsg",
 			Help: "Number of authenticating transitions due to EAP response or identity message",
-		})
-	onosaaaTxAuthSuccess = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaTxAuthSuccess = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_tx_auth_Success",
 			Help: "Number of authenticated transitions due to successful authentication",
-		})
-	onosaaaTxAuthFailure = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaTxAuthFailure = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_tx_auth_Failure",
 			Help: "Number of transitions to held due to authentication failure",
-		})
-	onosaaaTxStartReq = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaTxStartReq = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_tx_start_Req",
 			Help: "Number of transitions to connecting due to start request",
-		})
-	onosaaaEapPktTxAuthChooseEap = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaEapPktTxAuthChooseEap = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_eap_Pkt_tx_auth_choosing_Eap",
 			Help: "Number of EAP request packets sent due to the authenticator choosing the EAP method",
-		})
-	onosaaaTxRespnotNak = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaTxRespnotNak = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_tx_Resp_not_Nak",
 			Help: "Number of transitions to response (received response other that NAK)",
-		})
-	onosaaaEapolFramesTx = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaEapolFramesTx = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_eapol_frames_tx",
 			Help: "Number of EAPOL frames transmitted",
-		})
-	onosaaaAuthStateIdle = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaAuthStateIdle = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_auth_state_idle",
 			Help: "Number of state machine status as Idle",
-		})
-	onosaaaRequestIdFramesTx = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaRequestIdFramesTx = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_tx_request_id_frames",
 			Help: "Number of request ID EAP frames transmitted",
-		})
-	onosaaaRequestEapFramesTx = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaRequestEapFramesTx = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_tx_request_eap_frames",
 			Help: "Number of request EAP frames transmitted",
-		})
-	onosaaaInvalidPktType = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaInvalidPktType = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_invalid_pkt_type",
 			Help: "Number of EAPOL frames received with invalid frame(Packet) type",
-		})
-	onosaaaInvalidBodyLength = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaInvalidBodyLength = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_invalid_body_length",
 			Help: "Number of EAPOL frames received with invalid body length",
-		})
-	onosaaaValidEapolFramesRx = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaValidEapolFramesRx = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_rx_valid_eapol_frames",
 			Help: "Number of valid EAPOL frames received",
-		})
-	onosaaaPendingResSupplicant = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaPendingResSupplicant = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_pending_response_supplicant",
 			Help: "Number of request pending response from supplicant",
-		})
-	onosaaaRxResIdEapFrames = prometheus.NewGauge(
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosaaaRxResIdEapFrames = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "onosaaa_rx_res_id_eap_frames",
 			Help: "Number of response ID EAP frames received",
-		})
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+
+	// onos dhcp l2 relay kpis
+	onosDhcpDiscoverTotal = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_dhcp_discover_total",
+			Help: "Number of DHCPDISCOVER packets relayed",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosDhcpOfferTotal = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_dhcp_offer_total",
+			Help: "Number of DHCPOFFER packets relayed",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosDhcpRequestTotal = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_dhcp_request_total",
+			Help: "Number of DHCPREQUEST packets relayed",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosDhcpAckTotal = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_dhcp_ack_total",
+			Help: "Number of DHCPACK packets relayed",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosDhcpNakTotal = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_dhcp_nak_total",
+			Help: "Number of DHCPNAK packets relayed",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosDhcpDeclineTotal = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_dhcp_decline_total",
+			Help: "Number of DHCPDECLINE packets relayed",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosDhcpReleaseTotal = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_dhcp_release_total",
+			Help: "Number of DHCPRELEASE packets relayed",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosDhcpInformTotal = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_dhcp_inform_total",
+			Help: "Number of DHCPINFORM packets relayed",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+
+	// onos igmp proxy kpis
+	onosIgmpJoinTotal = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_igmp_join_total",
+			Help: "Number of IGMP join requests received",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosIgmpLeaveTotal = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_igmp_leave_total",
+			Help: "Number of IGMP leave requests received",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosIgmpGeneralQueryTotal = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_igmp_general_query_total",
+			Help: "Number of IGMP general membership queries",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosIgmpGroupSpecificQueryTotal = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_igmp_group_specific_query_total",
+			Help: "Number of IGMP group specific membership queries",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosIgmpMembershipReportTotal = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_igmp_membership_report_total",
+			Help: "Number of IGMP membership reports received",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosIgmpInvalidPacketsTotal = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_igmp_invalid_packets_total",
+			Help: "Number of invalid IGMP packets received",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+
+	// onos multicast kpis
+	onosMcastActiveGroups = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_mcast_active_groups",
+			Help: "Number of active multicast groups",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+	onosMcastSinks = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_mcast_sinks",
+			Help: "Number of subscriber ports receiving multicast traffic",
+		},
+		[]string{"onos_instance", "device_id", "port_number"},
+	)
+
+	// onos.aaa authentication events
+	onosAaaSubscriberState = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_aaa_subscriber_state",
+			Help: "Authentication state of the subscriber (1 for the current state, 0 otherwise)",
+		},
+		[]string{"device_id", "port_number", "onu_serial", "mac_address", "state"},
+	)
+	onosAaaSubscriberStateTimestamp = newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "onos_aaa_subscriber_state_timestamp_seconds",
+			Help: "Time the subscriber entered its current authentication state",
+		},
+		[]string{"device_id", "port_number", "onu_serial", "mac_address"},
+	)
+	onosAaaSubscriberStateTransitions = newCounterVec(
+		prometheus.CounterOpts{
+			Name: "onos_aaa_subscriber_state_transitions_total",
+			Help: "Number of subscriber authentication state transitions",
+		},
+		[]string{"device_id", "from_state", "to_state"},
+	)
 	//OLT Device Metrics
 	//TODO: Check if component level temperatures are supported by Devices,If not remove in later versions of exporter
-	oltDeviceCpuTemp = prometheus.NewGaugeVec(
+	oltDeviceCpuTemp = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "olt_device_cpu_temperature",
 			Help: "cpu temperature",
@@ -853,42 +1242,42 @@ var (
 		[]string{"deviceuuid", "componentuuid", "componentname"},
 	)
 
-	oltDeviceCpuUsagePercent = prometheus.NewGaugeVec(
+	oltDeviceCpuUsagePercent = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "olt_device_cpu_usage_percentage",
 			Help: "usage of cpu",
 		},
 		[]string{"deviceuuid", "componentuuid", "componentname"},
 	)
-	oltDeviceFanSpeed = prometheus.NewGaugeVec(
+	oltDeviceFanSpeed = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "olt_device_fan_speed",
 			Help: "fan speed",
 		},
 		[]string{"deviceuuid", "componentuuid", "componentname"},
 	)
-	oltDeviceDiskTemp = prometheus.NewGaugeVec(
+	oltDeviceDiskTemp = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "olt_device_disk_temp",
 			Help: "disk temperature",
 		},
 		[]string{"deviceuuid", "componentuuid", "componentname"},
 	)
-	oltDeviceDiskUsagePercent = prometheus.NewGaugeVec(
+	oltDeviceDiskUsagePercent = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "olt_device_disk_usage_percent",
 			Help: "disk usage percentage",
 		},
 		[]string{"deviceuuid", "componentuuid", "componentname"},
 	)
-	oltDeviceRamTemp = prometheus.NewGaugeVec(
+	oltDeviceRamTemp = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "olt_device_ram_temp",
 			Help: "RAM temperature",
 		},
 		[]string{"deviceuuid", "componentuuid", "componentname"},
 	)
-	oltDeviceRamUsagePercent = prometheus.NewGaugeVec(
+	oltDeviceRamUsagePercent = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "olt_device_ram_usage_percentage",
 			Help: "RAM usage percentage",
@@ -896,7 +1285,7 @@ var (
 		[]string{"deviceuuid", "componentuuid", "componentname"},
 	)
 
-	oltDevicePowerUsagePercent = prometheus.NewGaugeVec(
+	oltDevicePowerUsagePercent = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "olt_device_power_usage_percentage",
 			Help: "power usage percentage",
@@ -904,7 +1293,7 @@ var (
 		[]string{"deviceuuid", "componentuuid", "componentname"},
 	)
 
-	oltDeviceInnerSurroundTemp = prometheus.NewGaugeVec(
+	oltDeviceInnerSurroundTemp = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "olt_device_inner_surrounding_temperature",
 			Help: "inner surrounding temperature",
@@ -912,7 +1301,7 @@ var (
 		[]string{"deviceuuid", "componentuuid", "componentname"},
 	)
 
-	oltDevicePowerUsage = prometheus.NewGaugeVec(
+	oltDevicePowerUsage = newGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "olt_device_power_usage",
 			Help: "power usage",
@@ -921,7 +1310,7 @@ var (
 	)
 )
 
-var oltDeviceMetrics = map[dmi.MetricNames]*prometheus.GaugeVec{
+var oltDeviceMetrics = map[dmi.MetricNames]*metricVec{
 	dmi.MetricNames_METRIC_CPU_TEMP:               oltDeviceCpuTemp,
 	dmi.MetricNames_METRIC_CPU_USAGE_PERCENTAGE:   oltDeviceCpuUsagePercent,
 	dmi.MetricNames_METRIC_FAN_SPEED:              oltDeviceFanSpeed,
@@ -934,14 +1323,70 @@ var oltDeviceMetrics = map[dmi.MetricNames]*prometheus.GaugeVec{
 	dmi.MetricNames_METRIC_POWER_USAGE:            oltDevicePowerUsage,
 }
 
+var volthaOnuEthFrameExtMetrics = map[string]*metricVec{
+	"drop_events":         volthaOnuEthFrameExtDropEvents,
+	"octets":              volthaOnuEthFrameExtOctets,
+	"frames":              volthaOnuEthFrameExtFrames,
+	"broadcast_frames":    volthaOnuEthFrameExtBroadcastFrames,
+	"multicast_frames":    volthaOnuEthFrameExtMulticastFrames,
+	"crc_errored_frames":  volthaOnuEthFrameExtCrcErroredFrames,
+	"undersize_frames":    volthaOnuEthFrameExtUndersizeFrames,
+	"oversize_frames":     volthaOnuEthFrameExtOversizeFrames,
+	"64_octets":           volthaOnuEthFrameExt64Octet,
+	"65_to_127_octets":    volthaOnuEthFrameExt65To127Octet,
+	"128_to_255_octets":   volthaOnuEthFrameExt128To255Octet,
+	"256_to_511_octets":   volthaOnuEthFrameExt256To511Octet,
+	"512_to_1023_octets":  volthaOnuEthFrameExt512To1023Octet,
+	"1024_to_1518_octets": volthaOnuEthFrameExt1024To1518Octet,
+}
+
+// volthaOltPortLabels returns the interface_id and pon_id labels of an OLT
+// NNI or PON port, decoded from the VOLTHA port number
+func volthaOltPortLabels(title string, portNo string) (string, string) {
+	interfaceID, ponID := "NA", "NA"
+	number, err := strconv.ParseUint(portNo, 10, 32)
+	if err != nil {
+		return interfaceID, ponID
+	}
+	switch title {
+	case "ETHERNET_NNI":
+		if intfID, ok := utils.NniIntfIDFromPortNo(uint32(number)); ok {
+			interfaceID = strconv.FormatUint(uint64(intfID), 10)
+		}
+	case "PON_OLT":
+		if intfID, ok := utils.PonIntfIDFromPortNo(uint32(number)); ok {
+			interfaceID = strconv.FormatUint(uint64(intfID), 10)
+			ponID = interfaceID
+		}
+	}
+	return interfaceID, ponID
+}
+
+// volthaOnuPonLabels returns the pon_id and port_number labels of ONU
+// metrics, from the context or decoded from the UNI port number
+func volthaOnuPonLabels(context map[string]string) (string, string) {
+	ponID, portNumber := "NA", "NA"
+	if portNo, ok := context["portno"]; ok {
+		portNumber = portNo
+		if number, err := strconv.ParseUint(portNo, 10, 32); err == nil {
+			ponID = strconv.FormatUint(uint64(utils.PonIntfIDFromUniPortNo(uint32(number))), 10)
+		}
+	}
+	if intfID, ok := context["intf_id"]; ok {
+		ponID = intfID
+	}
+	return ponID, portNumber
+}
+
 func exportVolthaEthernetPonStats(data *voltha.MetricInformation) {
+	interfaceID, ponID := volthaOltPortLabels(data.GetMetadata().GetTitle(), data.GetMetadata().GetContext()["portno"])
 
 	volthaOltTxBytesTotal.WithLabelValues(
 		data.GetMetadata().GetLogicalDeviceId(),
 		data.GetMetadata().GetSerialNo(),
 		data.GetMetadata().GetDeviceId(),
-		"NA", // InterfaceID
-		"NA", // PonID
+		interfaceID,
+		ponID,
 		data.GetMetadata().GetContext()["portno"],
 		data.GetMetadata().GetTitle(),
 	).Set(float64(data.GetMetrics()["TxBytes"]))
@@ -950,8 +1395,8 @@ func exportVolthaEthernetPonStats(data *voltha.MetricInformation) {
 		data.GetMetadata().GetLogicalDeviceId(),
 		data.GetMetadata().GetSerialNo(),
 		data.GetMetadata().GetDeviceId(),
-		"NA", // InterfaceID
-		"NA", // PonID
+		interfaceID,
+		ponID,
 		data.GetMetadata().GetContext()["portno"],
 		data.GetMetadata().GetTitle(),
 	).Set(float64(data.GetMetrics()["RxBytes"]))
@@ -960,8 +1405,8 @@ func exportVolthaEthernetPonStats(data *voltha.MetricInformation) {
 		data.GetMetadata().GetLogicalDeviceId(),
 		data.GetMetadata().GetSerialNo(),
 		data.GetMetadata().GetDeviceId(),
-		"NA", // InterfaceID
-		"NA", // PonID
+		interfaceID,
+		ponID,
 		data.GetMetadata().GetContext()["portno"],
 		data.GetMetadata().GetTitle(),
 	).Set(float64(data.GetMetrics()["TxPackets"]))
@@ -970,8 +1415,8 @@ func exportVolthaEthernetPonStats(data *voltha.MetricInformation) {
 		data.GetMetadata().GetLogicalDeviceId(),
 		data.GetMetadata().GetSerialNo(),
 		data.GetMetadata().GetDeviceId(),
-		"NA", // InterfaceID
-		"NA", // PonID
+		interfaceID,
+		ponID,
 		data.GetMetadata().GetContext()["portno"],
 		data.GetMetadata().GetTitle(),
 	).Set(float64(data.GetMetrics()["RxPackets"]))
@@ -980,8 +1425,8 @@ func exportVolthaEthernetPonStats(data *voltha.MetricInformation) {
 		data.GetMetadata().GetLogicalDeviceId(),
 		data.GetMetadata().GetSerialNo(),
 		data.GetMetadata().GetDeviceId(),
-		"NA", // InterfaceID
-		"NA", // PonID
+		interfaceID,
+		ponID,
 		data.GetMetadata().GetContext()["portno"],
 		data.GetMetadata().GetTitle(),
 	).Set(float64(data.GetMetrics()["TxErrorPackets"]))
@@ -990,8 +1435,8 @@ func exportVolthaEthernetPonStats(data *voltha.MetricInformation) {
 		data.GetMetadata().GetLogicalDeviceId(),
 		data.GetMetadata().GetSerialNo(),
 		data.GetMetadata().GetDeviceId(),
-		"NA", // InterfaceID
-		"NA", // PonID
+		interfaceID,
+		ponID,
 		data.GetMetadata().GetContext()["portno"],
 		data.GetMetadata().GetTitle(),
 	).Set(float64(data.GetMetrics()["RxErrorPackets"]))
@@ -1000,8 +1445,8 @@ func exportVolthaEthernetPonStats(data *voltha.MetricInformation) {
 		data.GetMetadata().GetLogicalDeviceId(),
 		data.GetMetadata().GetSerialNo(),
 		data.GetMetadata().GetDeviceId(),
-		"NA", // InterfaceID
-		"NA", // PonID
+		interfaceID,
+		ponID,
 		data.GetMetadata().GetContext()["portno"],
 		data.GetMetadata().GetTitle(),
 	).Set(float64(data.GetMetrics()["TxBcastPackets"]))
@@ -1010,8 +1455,8 @@ func exportVolthaEthernetPonStats(data *voltha.MetricInformation) {
 		data.GetMetadata().GetLogicalDeviceId(),
 		data.GetMetadata().GetSerialNo(),
 		data.GetMetadata().GetDeviceId(),
-		"NA", // InterfaceID
-		"NA", // PonID
+		interfaceID,
+		ponID,
 		data.GetMetadata().GetContext()["portno"],
 		data.GetMetadata().GetTitle(),
 	).Set(float64(data.GetMetrics()["TxUcastPackets"]))
@@ -1020,8 +1465,8 @@ func exportVolthaEthernetPonStats(data *voltha.MetricInformation) {
 		data.GetMetadata().GetLogicalDeviceId(),
 		data.GetMetadata().GetSerialNo(),
 		data.GetMetadata().GetDeviceId(),
-		"NA", // InterfaceID
-		"NA", // PonID
+		interfaceID,
+		ponID,
 		data.GetMetadata().GetContext()["portno"],
 		data.GetMetadata().GetTitle(),
 	).Set(float64(data.GetMetrics()["TxMcastPackets"]))
@@ -1030,8 +1475,8 @@ func exportVolthaEthernetPonStats(data *voltha.MetricInformation) {
 		data.GetMetadata().GetLogicalDeviceId(),
 		data.GetMetadata().GetSerialNo(),
 		data.GetMetadata().GetDeviceId(),
-		"NA", // InterfaceID
-		"NA", // PonID
+		interfaceID,
+		ponID,
 		data.GetMetadata().GetContext()["portno"],
 		data.GetMetadata().GetTitle(),
 	).Set(float64(data.GetMetrics()["RxBcastPackets"]))
@@ -1040,8 +1485,8 @@ func exportVolthaEthernetPonStats(data *voltha.MetricInformation) {
 		data.GetMetadata().GetLogicalDeviceId(),
 		data.GetMetadata().GetSerialNo(),
 		data.GetMetadata().GetDeviceId(),
-		"NA", // InterfaceID
-		"NA", // PonID
+		interfaceID,
+		ponID,
 		data.GetMetadata().GetContext()["portno"],
 		data.GetMetadata().GetTitle(),
 	).Set(float64(data.GetMetrics()["RxUcastPackets"]))
@@ -1050,14 +1495,15 @@ func exportVolthaEthernetPonStats(data *voltha.MetricInformation) {
 		data.GetMetadata().GetLogicalDeviceId(),
 		data.GetMetadata().GetSerialNo(),
 		data.GetMetadata().GetDeviceId(),
-		"NA", // InterfaceID
-		"NA", // PonID
+		interfaceID,
+		ponID,
 		data.GetMetadata().GetContext()["portno"],
 		data.GetMetadata().GetTitle(),
 	).Set(float64(data.GetMetrics()["RxMcastPackets"]))
 }
 
 func exportVolthaOnuEthernetBridgePortStats(data *voltha.MetricInformation) {
+	volthaAggregates.observeBridgePortStats(data)
 	onuSN := utils.GetOnuSN(data.GetMetadata().GetSerialNo())
 	if (data.GetMetadata().GetContext()["upstream"]) == "True" {
 		// ONU. Extended Ethernet statistics.
@@ -1267,14 +1713,16 @@ func exportVolthaOnuEthernetBridgePortStats(data *voltha.MetricInformation) {
 }
 
 func exportVolthaOnuPonOpticalStats(data *voltha.MetricInformation) {
+	volthaAggregates.observeOpticalStats(data)
 	onuSN := utils.GetOnuSN(data.GetMetadata().GetSerialNo())
+	ponID, portNumber := volthaOnuPonLabels(data.GetMetadata().GetContext())
 	VolthaOnuTransmtOpticalPower.WithLabelValues(
 		data.GetMetadata().GetLogicalDeviceId(),
 		onuSN,
 		data.GetMetadata().GetDeviceId(),
 		data.GetMetadata().GetContext()["intf_id"],
-		"NA", // PonID,
-		"NA", //PortNumber
+		ponID,
+		portNumber,
 		data.GetMetadata().GetTitle(),
 	).Set(float64(data.GetMetrics()["transmit_power"]))
 
@@ -1283,12 +1731,47 @@ func exportVolthaOnuPonOpticalStats(data *voltha.MetricInformation) {
 		onuSN,
 		data.GetMetadata().GetDeviceId(),
 		data.GetMetadata().GetContext()["intf_id"],
-		"NA", // PonID,
-		"NA", //PortNumber
+		ponID,
+		portNumber,
 		data.GetMetadata().GetTitle(),
 	).Set(float64(data.GetMetrics()["receive_power"]))
 }
+
+// exportVolthaOnuTestResultStats exports the ANI-G and ONU-G test results,
+// which are reported with their raw OMCI encoding
+func exportVolthaOnuTestResultStats(data *voltha.MetricInformation) {
+	onuSN := utils.GetOnuSN(data.GetMetadata().GetSerialNo())
+	ponID, portNumber := volthaOnuPonLabels(data.GetMetadata().GetContext())
+	labels := []string{
+		data.GetMetadata().GetLogicalDeviceId(),
+		onuSN,
+		data.GetMetadata().GetDeviceId(),
+		data.GetMetadata().GetContext()["intf_id"],
+		ponID,
+		portNumber,
+		data.GetMetadata().GetTitle(),
+	}
+	metrics := data.GetMetrics()
+
+	if value, ok := metrics["power_feed_voltage"]; ok {
+		VolthaOnuPowerFeedVoltage.WithLabelValues(labels...).Set(utils.OmciPowerFeedVoltage(float64(value)))
+	}
+	if value, ok := metrics["received_optical_power"]; ok {
+		VolthaOnuReceivedOpticalPower.WithLabelValues(labels...).Set(utils.OmciOpticalPower(float64(value)))
+	}
+	if value, ok := metrics["mean_optical_launch_power"]; ok {
+		VolthaOnuMeanOpticalLaunchPower.WithLabelValues(labels...).Set(utils.OmciOpticalPower(float64(value)))
+	}
+	if value, ok := metrics["laser_bias_current"]; ok {
+		VolthaOnuLaserBiasCurrent.WithLabelValues(labels...).Set(utils.OmciLaserBiasCurrent(float64(value)))
+	}
+	if value, ok := metrics["temperature"]; ok {
+		volthaOnuTemperature.WithLabelValues(labels...).Set(utils.OmciTemperature(float64(value)))
+	}
+}
+
 func exportVolthaOnuFecStats(data *voltha.MetricInformation) {
+	volthaAggregates.observeFecStats(data)
 	onuSN := utils.GetOnuSN(data.GetMetadata().GetSerialNo())
 	volthaOnuFecCorrectedCodewordsTotal.WithLabelValues(
 		data.GetMetadata().GetLogicalDeviceId(),
@@ -1325,6 +1808,159 @@ func exportVolthaOnuFecStats(data *voltha.MetricInformation) {
 		data.GetMetadata().GetTitle(),
 	).Set(float64(data.GetMetrics()["uncorrectable_code_words"]))
 }
+
+// omciStateUp maps an OMCI state, 0 for enabled/unlocked and 1 for
+// disabled/locked, to 1 for up and 0 for down
+func omciStateUp(value float32) (float64, bool) {
+	switch value {
+	case 0:
+		return 1, true
+	case 1:
+		return 0, true
+	}
+	return 0, false
+}
+
+// volthaUniPortNumber returns the UNI port number from the KpiEvent2 context
+func volthaUniPortNumber(context map[string]string) string {
+	if portNumber, ok := context["uni_port_no"]; ok {
+		return portNumber
+	}
+	return context["portno"]
+}
+
+func exportVolthaOnuEthernetFrameExtendedStats(data *voltha.MetricInformation) {
+	onuSN := utils.GetOnuSN(data.GetMetadata().GetSerialNo())
+	direction := "downstream"
+	if data.GetMetadata().GetContext()["upstream"] == "True" {
+		direction = "upstream"
+	}
+	labels := []string{
+		data.GetMetadata().GetLogicalDeviceId(),
+		onuSN,
+		data.GetMetadata().GetDeviceId(),
+		volthaUniPortNumber(data.GetMetadata().GetContext()),
+		direction,
+		data.GetMetadata().GetTitle(),
+	}
+
+	for name, value := range data.GetMetrics() {
+		if metric, ok := volthaOnuEthFrameExtMetrics[name]; ok {
+			metric.WithLabelValues(labels...).Set(float64(value))
+		}
+	}
+}
+
+func exportVolthaOnuGemPortStats(data *voltha.MetricInformation) {
+	onuSN := utils.GetOnuSN(data.GetMetadata().GetSerialNo())
+	context := data.GetMetadata().GetContext()
+	gemPortID, ok := context["gem_port_id"]
+	if !ok {
+		// the GEM port network CTP instance is the GEM port id
+		gemPortID = context["entity_id"]
+	}
+	labels := func(direction string) []string {
+		return []string{
+			data.GetMetadata().GetLogicalDeviceId(),
+			onuSN,
+			data.GetMetadata().GetDeviceId(),
+			gemPortID,
+			direction,
+			data.GetMetadata().GetTitle(),
+		}
+	}
+	metrics := data.GetMetrics()
+
+	// transmitted is upstream and received is downstream, seen from the ONU
+	if value, ok := metrics["transmitted_gem_frames"]; ok {
+		volthaOnuGemPortFrames.WithLabelValues(labels("upstream")...).Set(float64(value))
+	}
+	if value, ok := metrics["received_gem_frames"]; ok {
+		volthaOnuGemPortFrames.WithLabelValues(labels("downstream")...).Set(float64(value))
+	}
+	if value, ok := metrics["transmitted_payload_bytes"]; ok {
+		volthaOnuGemPortPayloadBytes.WithLabelValues(labels("upstream")...).Set(float64(value))
+	}
+	if value, ok := metrics["received_payload_bytes"]; ok {
+		volthaOnuGemPortPayloadBytes.WithLabelValues(labels("downstream")...).Set(float64(value))
+	}
+	if value, ok := metrics["encryption_key_errors"]; ok {
+		volthaOnuGemPortEncryptionKeyErrors.WithLabelValues(labels("downstream")...).Set(float64(value))
+	}
+}
+
+// PPTP Ethernet UNI configuration indication (ITU-T G.988 9.5.1)
+var volthaUniConfigInd = map[int]struct{ speed, duplex string }{
+	0x01: {"10M", "full"},
+	0x02: {"100M", "full"},
+	0x03: {"1G", "full"},
+	0x04: {"10G", "full"},
+	0x05: {"2.5G", "full"},
+	0x06: {"5G", "full"},
+	0x07: {"25G", "full"},
+	0x08: {"40G", "full"},
+	0x11: {"10M", "half"},
+	0x12: {"100M", "half"},
+	0x13: {"1G", "half"},
+}
+
+// last voltha_onu_uni_info labels per UNI, to remove the series when the
+// sensed type or speed changes
+var volthaUniInfoLabels = struct {
+	sync.Mutex
+	labels map[string][]string
+}{labels: make(map[string][]string)}
+
+func exportVolthaOnuUniStatus(data *voltha.MetricInformation) {
+	onuSN := utils.GetOnuSN(data.GetMetadata().GetSerialNo())
+	context := data.GetMetadata().GetContext()
+	labels := []string{
+		data.GetMetadata().GetLogicalDeviceId(),
+		onuSN,
+		data.GetMetadata().GetDeviceId(),
+		volthaUniPortNumber(context),
+		context["entity_id"],
+	}
+	metrics := data.GetMetrics()
+
+	if value, ok := metrics["oper_status"]; ok {
+		if up, ok := omciStateUp(value); ok {
+			volthaOnuUniOperState.WithLabelValues(labels...).Set(up)
+		} else {
+			logger.Warn("Ignoring unknown UNI oper_status [%v] for ONU [%s]", value, onuSN)
+		}
+	}
+	if value, ok := metrics["uni_admin_state"]; ok {
+		if up, ok := omciStateUp(value); ok {
+			volthaOnuUniAdminState.WithLabelValues(labels...).Set(up)
+		} else {
+			logger.Warn("Ignoring unknown UNI uni_admin_state [%v] for ONU [%s]", value, onuSN)
+		}
+	}
+
+	sensedType := "unknown"
+	if value, ok := metrics["ethernet_type"]; ok {
+		sensedType = strconv.Itoa(int(value))
+	}
+	speed, duplex := "unknown", "unknown"
+	if value, ok := metrics["configuration_ind"]; ok {
+		if configInd, ok := volthaUniConfigInd[int(value)]; ok {
+			speed, duplex = configInd.speed, configInd.duplex
+		}
+	}
+	infoLabels := append(append([]string{}, labels...), sensedType, speed, duplex)
+
+	key := strings.Join(labels, "/")
+	volthaUniInfoLabels.Lock()
+	if old, ok := volthaUniInfoLabels.labels[key]; ok && strings.Join(old, "/") != strings.Join(infoLabels, "/") {
+		volthaOnuUniInfo.DeleteLabelValues(old...)
+	}
+	volthaUniInfoLabels.labels[key] = infoLabels
+	volthaUniInfoLabels.Unlock()
+
+	volthaOnuUniInfo.WithLabelValues(infoLabels...).Set(1)
+}
+
 func exportVolthaOnuEthernetUniStats(data *voltha.MetricInformation) {
 	onuSN := utils.GetOnuSN(data.GetMetadata().GetSerialNo())
 
@@ -1447,6 +2083,12 @@ func exportVolthaOnuEthernetUniStats(data *voltha.MetricInformation) {
 
 func exportVolthaKPIevent2(kpi *voltha.KpiEvent2) {
 	for _, data := range kpi.GetSliceData() {
+		inventory.learnVolthaDevice(data)
+		ts := volthaTimestamp(data.GetMetadata().GetTs())
+		if ts.IsZero() {
+			ts = volthaTimestamp(kpi.GetTs())
+		}
+		sourceTimestamps.observe(data.GetMetadata().GetDeviceId(), ts)
 		switch title := data.GetMetadata().GetTitle(); title {
 		case "ETHERNET_NNI", "PON_OLT":
 			exportVolthaEthernetPonStats(data)
@@ -1458,14 +2100,22 @@ func exportVolthaKPIevent2(kpi *voltha.KpiEvent2) {
 			exportVolthaOnuEthernetUniStats(data)
 		case "FEC_History":
 			exportVolthaOnuFecStats(data)
+		case "ANI_G", "ONU_G":
+			exportVolthaOnuTestResultStats(data)
 		case "UNI_Status":
-			//  Do nothing.
-
+			exportVolthaOnuUniStatus(data)
+		case "Ethernet_Frame_Extended_PM":
+			exportVolthaOnuEthernetFrameExtendedStats(data)
+		case "GEM_Port_History":
+			exportVolthaOnuGemPortStats(data)
+		default:
+			exportVolthaPassthroughStats(data)
 		}
 	}
 }
 
 func exportOnosKPI(kpi OnosKPI) {
+	sourceTimestamps.observe(kpi.DeviceID, time.Time(kpi.Timestamp))
 
 	for _, data := range kpi.Ports {
 
@@ -1501,6 +2151,85 @@ func exportOnosKPI(kpi OnosKPI) {
 	}
 }
 
+func deleteOnosPortMetrics(deviceID string, portID string, info *OnosPortInfo) {
+	onosPortInfo.DeleteLabelValues(
+		deviceID,
+		portID,
+		info.Name,
+		info.Type,
+		info.Speed,
+		info.OnuSerial,
+	)
+	onosPortEnabled.DeleteLabelValues(deviceID, portID)
+}
+
+func exportOnosEvent(event OnosEvent) {
+	sourceTimestamps.observe(event.DeviceID, time.Time(event.Timestamp))
+
+	switch event.Type {
+	case "DEVICE_REMOVED":
+		for portID, info := range onosPorts.removeDevice(event.DeviceID) {
+			deleteOnosPortMetrics(event.DeviceID, portID, info)
+		}
+		for _, sub := range onosAaaSubscribers.removePort(event.DeviceID, "") {
+			deleteOnosAaaSubscriberMetrics(sub)
+		}
+		return
+	case "PORT_REMOVED":
+		if event.Port == nil {
+			break
+		}
+		if info := onosPorts.remove(event.DeviceID, event.Port.PortID); info != nil {
+			deleteOnosPortMetrics(event.DeviceID, event.Port.PortID, info)
+		}
+		for _, sub := range onosAaaSubscribers.removePort(event.DeviceID, event.Port.PortID) {
+			deleteOnosAaaSubscriberMetrics(sub)
+		}
+		return
+	}
+
+	// DEVICE_ADDED, PORT_ADDED, PORT_UPDATED and the like carry the port
+	if event.Port == nil {
+		logger.Debug("Ignoring ONOS event [%s] for device [%s], no port information", event.Type, event.DeviceID)
+		return
+	}
+
+	portName := event.Port.Annotations["portName"]
+	info := &OnosPortInfo{
+		Name:    portName,
+		Type:    event.Port.Type,
+		Speed:   strconv.FormatFloat(event.Port.Speed, 'f', -1, 64),
+		Enabled: event.Port.Enabled,
+	}
+	if serial := onuSerialFromPortName(portName); serial != "" {
+		info.OnuSerial = utils.GetOnuSN(serial)
+	}
+
+	inventory.learnOnosDevice(info.OnuSerial, event.DeviceID)
+
+	if old := onosPorts.update(event.DeviceID, event.Port.PortID, info); old != nil && *old != *info {
+		deleteOnosPortMetrics(event.DeviceID, event.Port.PortID, old)
+	}
+
+	onosPortInfo.WithLabelValues(
+		event.DeviceID,
+		event.Port.PortID,
+		info.Name,
+		info.Type,
+		info.Speed,
+		info.OnuSerial,
+	).Set(1)
+
+	enabled := 0.0
+	if info.Enabled {
+		enabled = 1
+	}
+	onosPortEnabled.WithLabelValues(
+		event.DeviceID,
+		event.Port.PortID,
+	).Set(enabled)
+}
+
 func exportImporterKPI(kpi ImporterKPI) {
 	deviceLaserBiasCurrent.WithLabelValues(
 		kpi.PortId,
@@ -1517,6 +2246,11 @@ func exportImporterKPI(kpi ImporterKPI) {
 }
 
 func exportDeviceKPI(kpi *dmi.Metric) {
+	if ts := kpi.GetValue().GetTimestamp(); ts != nil {
+		sourceTimestamps.observe(kpi.GetMetricMetadata().GetDeviceUuid().GetUuid(), time.Unix(ts.GetSeconds(), int64(ts.GetNanos())))
+	}
+
+	inventory.learnDmiDevice(kpi.GetMetricMetadata().GetDeviceUuid().GetUuid())
 
 	if metrics, ok := oltDeviceMetrics[kpi.GetMetricId()]; ok {
 		metrics.WithLabelValues(
@@ -1528,62 +2262,212 @@ func exportDeviceKPI(kpi *dmi.Metric) {
 }
 
 func exportOnosAaaKPI(kpi OnosAaaKPI) {
+	// statistics without device or port are the global ones of the
+	// ONOS instance, they are exported with empty labels
+	labels := []string{kpi.InstanceID, kpi.DeviceID, kpi.PortNumber}
+
+	onosaaaRxAcceptResponses.WithLabelValues(labels...).Set(kpi.RxAcceptResponses)
+
+	onosaaaRxRejectResponses.WithLabelValues(labels...).Set(kpi.RxRejectResponses)
+
+	onosaaaRxChallengeResponses.WithLabelValues(labels...).Set(kpi.RxChallengeResponses)
+
+	onosaaaTxAccessRequests.WithLabelValues(labels...).Set(kpi.TxAccessRequests)
 
-	onosaaaRxAcceptResponses.Set(kpi.RxAcceptResponses)
+	onosaaaRxInvalidValidators.WithLabelValues(labels...).Set(kpi.RxInvalidValidators)
 
-	onosaaaRxRejectResponses.Set(kpi.RxRejectResponses)
+	onosaaaRxUnknownType.WithLabelValues(labels...).Set(kpi.RxUnknownType)
 
-	onosaaaRxChallengeResponses.Set(kpi.RxChallengeResponses)
+	onosaaaPendingRequests.WithLabelValues(labels...).Set(kpi.PendingRequests)
 
-	onosaaaTxAccessRequests.Set(kpi.TxAccessRequests)
+	onosaaaRxDroppedResponses.WithLabelValues(labels...).Set(kpi.RxDroppedResponses)
 
-	onosaaaRxInvalidValidators.Set(kpi.RxInvalidValidators)
+	onosaaaRxMalformedResponses.WithLabelValues(labels...).Set(kpi.RxMalformedResponses)
 
-	onosaaaRxUnknownType.Set(kpi.RxUnknownType)
+	onosaaaRxUnknownserver.WithLabelValues(labels...).Set(kpi.RxUnknownserver)
 
-	onosaaaPendingRequests.Set(kpi.PendingRequests)
+	onosaaaRequestRttMillis.WithLabelValues(labels...).Set(kpi.RequestRttMillis)
 
-	onosaaaRxDroppedResponses.Set(kpi.RxDroppedResponses)
+	onosaaaRequestReTx.WithLabelValues(labels...).Set(kpi.RequestReTx)
 
-	onosaaaRxMalformedResponses.Set(kpi.RxMalformedResponses)
+	onosaaaRxEapolLogoff.WithLabelValues(labels...).Set(kpi.RxEapolLogoff)
 
-	onosaaaRxUnknownserver.Set(kpi.RxUnknownserver)
+	onosaaaTxEapolResIdentityMsg.WithLabelValues(labels...).Set(kpi.TxEapolResIdentityMsg)
 
-	onosaaaRequestRttMillis.Set(kpi.RequestRttMillis)
+	onosaaaTxAuthSuccess.WithLabelValues(labels...).Set(kpi.TxAuthSuccess)
 
-	onosaaaRequestReTx.Set(kpi.RequestReTx)
+	onosaaaTxAuthFailure.WithLabelValues(labels...).Set(kpi.TxAuthFailure)
 
-	onosaaaRxEapolLogoff.Set(kpi.RxEapolLogoff)
+	onosaaaTxStartReq.WithLabelValues(labels...).Set(kpi.TxStartReq)
 
-	onosaaaTxEapolResIdentityMsg.Set(kpi.TxEapolResIdentityMsg)
+	onosaaaEapPktTxAuthChooseEap.WithLabelValues(labels...).Set(kpi.EapPktTxAuthChooseEap)
 
-	onosaaaTxAuthSuccess.Set(kpi.TxAuthSuccess)
+	onosaaaTxRespnotNak.WithLabelValues(labels...).Set(kpi.TxResponseNotNak)
 
-	onosaaaTxAuthFailure.Set(kpi.TxAuthFailure)
+	onosaaaEapolFramesTx.WithLabelValues(labels...).Set(kpi.EapolFramesTx)
 
-	onosaaaTxStartReq.Set(kpi.TxStartReq)
+	onosaaaAuthStateIdle.WithLabelValues(labels...).Set(kpi.AuthStateIdle)
 
-	onosaaaEapPktTxAuthChooseEap.Set(kpi.EapPktTxAuthChooseEap)
+	onosaaaRequestIdFramesTx.WithLabelValues(labels...).Set(kpi.RequestIdFramesTx)
 
-	onosaaaTxRespnotNak.Set(kpi.TxResponseNotNak)
+	onosaaaRequestEapFramesTx.WithLabelValues(labels...).Set(kpi.RequestEapFramesTx)
+
+	onosaaaInvalidPktType.WithLabelValues(labels...).Set(kpi.InvalidPktType)
+
+	onosaaaInvalidBodyLength.WithLabelValues(labels...).Set(kpi.InvalidBodyLength)
+
+	onosaaaValidEapolFramesRx.WithLabelValues(labels...).Set(kpi.ValidEapolFramesRx)
+
+	onosaaaPendingResSupplicant.WithLabelValues(labels...).Set(kpi.PendingResSupplicant)
+
+	onosaaaRxResIdEapFrames.WithLabelValues(labels...).Set(kpi.RxResIdEapFrames)
+}
 
-	onosaaaEapolFramesTx.Set(kpi.EapolFramesTx)
+func exportOnosDhcpKPI(kpi OnosDhcpKPI) {
+	labels := []string{kpi.InstanceID, kpi.DeviceID, kpi.PortNumber}
+
+	onosDhcpDiscoverTotal.WithLabelValues(labels...).Set(kpi.Discover)
+
+	onosDhcpOfferTotal.WithLabelValues(labels...).Set(kpi.Offer)
+
+	onosDhcpRequestTotal.WithLabelValues(labels...).Set(kpi.Request)
+
+	onosDhcpAckTotal.WithLabelValues(labels...).Set(kpi.Ack)
+
+	onosDhcpNakTotal.WithLabelValues(labels...).Set(kpi.Nak)
+
+	onosDhcpDeclineTotal.WithLabelValues(labels...).Set(kpi.Decline)
+
+	onosDhcpReleaseTotal.WithLabelValues(labels...).Set(kpi.Release)
+
+	onosDhcpInformTotal.WithLabelValues(labels...).Set(kpi.Inform)
+}
+
+func exportOnosIgmpKPI(kpi OnosIgmpKPI) {
+	labels := []string{kpi.InstanceID, kpi.DeviceID, kpi.PortNumber}
+
+	onosIgmpJoinTotal.WithLabelValues(labels...).Set(kpi.Join)
+
+	onosIgmpLeaveTotal.WithLabelValues(labels...).Set(kpi.Leave)
+
+	onosIgmpGeneralQueryTotal.WithLabelValues(labels...).Set(kpi.GeneralQuery)
+
+	onosIgmpGroupSpecificQueryTotal.WithLabelValues(labels...).Set(kpi.GroupSpecificQuery)
+
+	onosIgmpMembershipReportTotal.WithLabelValues(labels...).Set(kpi.MembershipReport)
+
+	onosIgmpInvalidPacketsTotal.WithLabelValues(labels...).Set(kpi.InvalidPackets)
+}
+
+func exportOnosMcastKPI(kpi OnosMcastKPI) {
+	labels := []string{kpi.InstanceID, kpi.DeviceID, kpi.PortNumber}
+
+	onosMcastActiveGroups.WithLabelValues(labels...).Set(kpi.ActiveGroups)
+
+	onosMcastSinks.WithLabelValues(labels...).Set(kpi.Sinks)
+}
+
+func deleteOnosAaaSubscriberMetrics(sub *onosAaaSubscriber) {
+	for _, state := range onosAaaAuthStates {
+		onosAaaSubscriberState.DeleteLabelValues(
+			sub.DeviceID,
+			sub.PortNumber,
+			sub.OnuSerial,
+			sub.Mac,
+			state,
+		)
+	}
+	onosAaaSubscriberStateTimestamp.DeleteLabelValues(
+		sub.DeviceID,
+		sub.PortNumber,
+		sub.OnuSerial,
+		sub.Mac,
+	)
+}
 
-	onosaaaAuthStateIdle.Set(kpi.AuthStateIdle)
+func exportOnosAaaAuthEvent(event OnosAaaAuthEvent) {
+	state := strings.ToUpper(event.State)
+	if !isOnosAaaAuthState(state) {
+		logger.Warn("Ignoring unknown authentication state [%s] for device [%s] port [%s]", event.State, event.DeviceID, event.PortNumber)
+		return
+	}
 
-	onosaaaRequestIdFramesTx.Set(kpi.RequestIdFramesTx)
+	sub := &onosAaaSubscriber{
+		DeviceID:   event.DeviceID,
+		PortNumber: event.PortNumber,
+		Mac:        event.SupplicantMac,
+		State:      state,
+	}
+	if event.SerialNumber != "" {
+		sub.OnuSerial = utils.GetOnuSN(event.SerialNumber)
+	} else if info, ok := onosPorts.get(event.DeviceID, event.PortNumber); ok {
+		// fall back to the serial number learned from onos.events
+		sub.OnuSerial = info.OnuSerial
+	}
+	inventory.learnOnosDevice(sub.OnuSerial, sub.DeviceID)
 
-	onosaaaRequestEapFramesTx.Set(kpi.RequestEapFramesTx)
+	// a subscriber that logged off is gone, only the transition is kept
+	logoff := state == "LOGOFF"
+	var old *onosAaaSubscriber
+	if logoff {
+		old = onosAaaSubscribers.remove(sub)
+	} else {
+		old = onosAaaSubscribers.update(sub)
+	}
 
-	onosaaaInvalidPktType.Set(kpi.InvalidPktType)
+	fromState := "NONE"
+	if old != nil {
+		fromState = old.State
+		if logoff || old.OnuSerial != sub.OnuSerial {
+			deleteOnosAaaSubscriberMetrics(old)
+		}
+	}
 
-	onosaaaInvalidBodyLength.Set(kpi.InvalidBodyLength)
+	if logoff {
+		if fromState != state {
+			onosAaaSubscriberStateTransitions.WithLabelValues(
+				sub.DeviceID,
+				fromState,
+				state,
+			).Inc()
+		}
+		return
+	}
 
-	onosaaaValidEapolFramesRx.Set(kpi.ValidEapolFramesRx)
+	for _, s := range onosAaaAuthStates {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		onosAaaSubscriberState.WithLabelValues(
+			sub.DeviceID,
+			sub.PortNumber,
+			sub.OnuSerial,
+			sub.Mac,
+			s,
+		).Set(value)
+	}
 
-	onosaaaPendingResSupplicant.Set(kpi.PendingResSupplicant)
+	if fromState == state {
+		return
+	}
 
-	onosaaaRxResIdEapFrames.Set(kpi.RxResIdEapFrames)
+	ts := time.Now()
+	if event.Timestamp > 0 {
+		ts = time.Unix(0, event.Timestamp*int64(time.Millisecond))
+	}
+	onosAaaSubscriberStateTimestamp.WithLabelValues(
+		sub.DeviceID,
+		sub.PortNumber,
+		sub.OnuSerial,
+		sub.Mac,
+	).Set(float64(ts.UnixNano()) / float64(time.Second))
+
+	onosAaaSubscriberStateTransitions.WithLabelValues(
+		sub.DeviceID,
+		fromState,
+		state,
+	).Inc()
 }
 
 func exportOnosBngKPI(kpi OnosBngKPI) {
@@ -1597,154 +2481,119 @@ func exportOnosBngKPI(kpi OnosBngKPI) {
 		"onuSerialNumber": kpi.OnuSerialNumber,
 	}).Trace("Received OnosBngKPI message")
 
-	if kpi.UpTxBytes != nil {
-		onosBngUpTxBytes.WithLabelValues(
-			kpi.Mac,
-			kpi.Ip,
-			strconv.Itoa(kpi.PppoeSessionId),
-			strconv.Itoa(kpi.STag),
-			strconv.Itoa(kpi.CTag),
-			kpi.OnuSerialNumber,
-			kpi.AttachmentType,
-		).Set(*kpi.UpTxBytes)
+	session := &bngSession{
+		Mac:            kpi.Mac,
+		SessionID:      strconv.Itoa(kpi.PppoeSessionId),
+		STag:           strconv.Itoa(kpi.STag),
+		CTag:           strconv.Itoa(kpi.CTag),
+		Ip:             kpi.Ip,
+		AttachmentType: kpi.AttachmentType,
+		DeviceID:       kpi.DeviceId,
+		PortNumber:     kpi.PortNumber,
 	}
-	if kpi.UpTxPackets != nil {
-		onosBngUpTxPackets.WithLabelValues(
-			kpi.Mac,
-			kpi.Ip,
-			strconv.Itoa(kpi.PppoeSessionId),
-			strconv.Itoa(kpi.STag),
-			strconv.Itoa(kpi.CTag),
-			kpi.OnuSerialNumber,
-			kpi.AttachmentType,
-		).Set(*kpi.UpTxPackets)
-	}
-	if kpi.UpRxBytes != nil {
-		onosBngUpRxBytes.WithLabelValues(
-			kpi.Mac,
-			kpi.Ip,
-			strconv.Itoa(kpi.PppoeSessionId),
-			strconv.Itoa(kpi.STag),
-			strconv.Itoa(kpi.CTag),
-			kpi.OnuSerialNumber,
-			kpi.AttachmentType,
-		).Set(*kpi.UpRxBytes)
-	}
-	if kpi.UpRxPackets != nil {
-		onosBngUpRxPackets.WithLabelValues(
-			kpi.Mac,
-			kpi.Ip,
-			strconv.Itoa(kpi.PppoeSessionId),
-			strconv.Itoa(kpi.STag),
-			strconv.Itoa(kpi.CTag),
-			kpi.OnuSerialNumber,
-			kpi.AttachmentType,
-		).Set(*kpi.UpRxPackets)
+	if kpi.OnuSerialNumber != "" {
+		session.OnuSerial = utils.GetOnuSN(kpi.OnuSerialNumber)
 	}
+	inventory.learnOnosDevice(session.OnuSerial, session.DeviceID)
 
-	if kpi.UpDropBytes != nil {
-		onosBngUpDropBytes.WithLabelValues(
-			kpi.Mac,
-			kpi.Ip,
-			strconv.Itoa(kpi.PppoeSessionId),
-			strconv.Itoa(kpi.STag),
-			strconv.Itoa(kpi.CTag),
-			kpi.OnuSerialNumber,
-			kpi.AttachmentType,
-		).Set(*kpi.UpDropBytes)
-	}
-	if kpi.UpDropPackets != nil {
-		onosBngUpDropPackets.WithLabelValues(
-			kpi.Mac,
-			kpi.Ip,
-			strconv.Itoa(kpi.PppoeSessionId),
-			strconv.Itoa(kpi.STag),
-			strconv.Itoa(kpi.CTag),
-			kpi.OnuSerialNumber,
-			kpi.AttachmentType,
-		).Set(*kpi.UpDropPackets)
+	start, ok := parseBngTimestamp(kpi.Timestamp)
+	if !ok {
+		start = time.Now()
+	} else {
+		sourceTimestamps.observe(session.Mac, start)
 	}
 
-	if kpi.ControlPackets != nil {
-		onosBngControlPackets.WithLabelValues(
-			kpi.Mac,
-			kpi.Ip,
-			strconv.Itoa(kpi.PppoeSessionId),
-			strconv.Itoa(kpi.STag),
-			strconv.Itoa(kpi.CTag),
-			kpi.OnuSerialNumber,
-			kpi.AttachmentType,
-		).Set(*kpi.ControlPackets)
+	stored, old := bngSessions.update(session, start)
+	session = &stored
+	if old == nil {
+		onosBngSessionStartTime.WithLabelValues(session.labels()...).Set(float64(session.Start.Unix()))
+		onosBngActiveSessions.WithLabelValues(session.AttachmentType).Inc()
+		onosBngActiveSessionsPerSTag.WithLabelValues(session.STag).Inc()
+	} else if !old.sameInfo(session) {
+		onosBngSessionInfo.DeleteLabelValues(old.infoLabels()...)
+		if old.AttachmentType != session.AttachmentType {
+			onosBngActiveSessions.WithLabelValues(old.AttachmentType).Dec()
+			onosBngActiveSessions.WithLabelValues(session.AttachmentType).Inc()
+		}
 	}
-
-	if kpi.DownTxBytes != nil {
-		onosBngDownTxBytes.WithLabelValues(
-			kpi.Mac,
-			kpi.Ip,
-			strconv.Itoa(kpi.PppoeSessionId),
-			strconv.Itoa(kpi.STag),
-			strconv.Itoa(kpi.CTag),
-			kpi.OnuSerialNumber,
-			kpi.AttachmentType,
-		).Set(*kpi.DownTxBytes)
+	onosBngSessionInfo.WithLabelValues(session.infoLabels()...).Set(1)
+
+	stats := []struct {
+		counter *metricVec
+		name    string
+		value   *float64
+	}{
+		{onosBngUpTxBytesTotal, "UpTxBytes", kpi.UpTxBytes},
+		{onosBngUpTxPacketsTotal, "UpTxPackets", kpi.UpTxPackets},
+		{onosBngUpRxBytesTotal, "UpRxBytes", kpi.UpRxBytes},
+		{onosBngUpRxPacketsTotal, "UpRxPackets", kpi.UpRxPackets},
+		{onosBngUpDropBytesTotal, "UpDropBytes", kpi.UpDropBytes},
+		{onosBngUpDropPacketsTotal, "UpDropPackets", kpi.UpDropPackets},
+		{onosBngControlPacketsTotal, "ControlPackets", kpi.ControlPackets},
+		{onosBngDownTxBytesTotal, "DownTxBytes", kpi.DownTxBytes},
+		{onosBngDownTxPacketsTotal, "DownTxPackets", kpi.DownTxPackets},
+		{onosBngDownRxBytesTotal, "DownRxBytes", kpi.DownRxBytes},
+		{onosBngDownRxPacketsTotal, "DownRxPackets", kpi.DownRxPackets},
+		{onosBngDownDropBytesTotal, "DownDropBytes", kpi.DownDropBytes},
+		{onosBngDownDropPacketsTotal, "DownDropPackets", kpi.DownDropPackets},
 	}
-	if kpi.DownTxPackets != nil {
-		onosBngDownTxPackets.WithLabelValues(
-			kpi.Mac,
-			kpi.Ip,
-			strconv.Itoa(kpi.PppoeSessionId),
-			strconv.Itoa(kpi.STag),
-			strconv.Itoa(kpi.CTag),
-			kpi.OnuSerialNumber,
-			kpi.AttachmentType,
-		).Set(*kpi.DownTxPackets)
+	for _, stat := range stats {
+		if stat.value != nil {
+			stat.counter.WithLabelValues(session.labels()...).Add(bngSessions.delta(session.key(), stat.name, *stat.value))
+		}
 	}
+}
 
-	if kpi.DownRxBytes != nil {
-		onosBngDownRxBytes.WithLabelValues(
-			kpi.Mac,
-			kpi.Ip,
-			strconv.Itoa(kpi.PppoeSessionId),
-			strconv.Itoa(kpi.STag),
-			strconv.Itoa(kpi.CTag),
-			kpi.OnuSerialNumber,
-			kpi.AttachmentType,
-		).Set(*kpi.DownRxBytes)
-	}
-	if kpi.DownRxPackets != nil {
-		onosBngDownRxPackets.WithLabelValues(
-			kpi.Mac,
-			kpi.Ip,
-			strconv.Itoa(kpi.PppoeSessionId),
-			strconv.Itoa(kpi.STag),
-			strconv.Itoa(kpi.CTag),
-			kpi.OnuSerialNumber,
-			kpi.AttachmentType,
-		).Set(*kpi.DownRxPackets)
+// expireBngSessions removes the series of the sessions that are no longer
+// reported on bng.stats
+func expireBngSessions() {
+	ticker := time.NewTicker(bngSessionSweepInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, session := range bngSessions.expire(time.Now().Add(-bngSessionTimeout)) {
+			logger.Debug("BNG session [%s] expired", session.key())
+			onosBngUpTxBytesTotal.DeleteLabelValues(session.labels()...)
+			onosBngUpTxPacketsTotal.DeleteLabelValues(session.labels()...)
+			onosBngUpRxBytesTotal.DeleteLabelValues(session.labels()...)
+			onosBngUpRxPacketsTotal.DeleteLabelValues(session.labels()...)
+			onosBngUpDropBytesTotal.DeleteLabelValues(session.labels()...)
+			onosBngUpDropPacketsTotal.DeleteLabelValues(session.labels()...)
+			onosBngControlPacketsTotal.DeleteLabelValues(session.labels()...)
+			onosBngDownTxBytesTotal.DeleteLabelValues(session.labels()...)
+			onosBngDownTxPacketsTotal.DeleteLabelValues(session.labels()...)
+			onosBngDownRxBytesTotal.DeleteLabelValues(session.labels()...)
+			onosBngDownRxPacketsTotal.DeleteLabelValues(session.labels()...)
+			onosBngDownDropBytesTotal.DeleteLabelValues(session.labels()...)
+			onosBngDownDropPacketsTotal.DeleteLabelValues(session.labels()...)
+			onosBngSessionInfo.DeleteLabelValues(session.infoLabels()...)
+			onosBngSessionStartTime.DeleteLabelValues(session.labels()...)
+			onosBngActiveSessions.WithLabelValues(session.AttachmentType).Dec()
+			onosBngActiveSessionsPerSTag.WithLabelValues(session.STag).Dec()
+		}
 	}
+}
 
-	if kpi.DownDropBytes != nil {
-		onosBngDownDropBytes.WithLabelValues(
-			kpi.Mac,
-			kpi.Ip,
-			strconv.Itoa(kpi.PppoeSessionId),
-			strconv.Itoa(kpi.STag),
-			strconv.Itoa(kpi.CTag),
-			kpi.OnuSerialNumber,
-			kpi.AttachmentType,
-		).Set(*kpi.DownDropBytes)
+// importerReading returns the reading of a TransceiverStatistics entry
+func importerReading(stats map[string]interface{}, name string) (float64, bool) {
+	entry, ok := stats[name].(map[string]interface{})
+	if !ok {
+		return 0, false
 	}
-	if kpi.DownDropPackets != nil {
-		onosBngDownDropPackets.WithLabelValues(
-			kpi.Mac,
-			kpi.Ip,
-			strconv.Itoa(kpi.PppoeSessionId),
-			strconv.Itoa(kpi.STag),
-			strconv.Itoa(kpi.CTag),
-			kpi.OnuSerialNumber,
-			kpi.AttachmentType,
-		).Set(*kpi.DownDropPackets)
+	reading, ok := entry["Reading"].(float64)
+	return reading, ok
+}
+
+// splitOnosStats returns the statistics objects of an ONOS stats message,
+// which is either a single object or a list of per device/port objects
+func splitOnosStats(data []byte) ([]json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var stats []json.RawMessage
+		err := json.Unmarshal(trimmed, &stats)
+		return stats, err
 	}
+	return []json.RawMessage{trimmed}, nil
 }
 
 func export(topic *string, data []byte) {
@@ -1770,10 +2619,22 @@ func export(topic *string, data []byte) {
 			break
 		}
 		exportOnosKPI(kpi)
+	case "onos.events":
+		event := OnosEvent{}
+		err := json.Unmarshal(data, &event)
+		if err != nil {
+			logger.Error("Invalid msg on onos.events: %s, Unprocessed Msg: %s", err.Error(), string(data))
+			break
+		}
+		exportOnosEvent(event)
 	case "importer":
 		kpi := ImporterKPI{}
 		strData := string(data)
 		idx := strings.Index(strData, "{")
+		if idx < 0 {
+			logger.Error("Invalid msg on importer: no JSON object, Unprocessed Msg: %s", strData)
+			break
+		}
 		strData = strData[idx:]
 
 		var m map[string]interface{}
@@ -1783,27 +2644,94 @@ func export(topic *string, data []byte) {
 			logger.Debug("Unprocessed Msg: %s", strData)
 			break
 		}
-		if val, ok := m["TransceiverStatistics"]; ok {
-			stats := val.(map[string]interface{})
-			kpi.LaserBiasCurrent = stats["BiasCurrent"].(map[string]interface{})["Reading"].(float64)
-			kpi.Temperature = stats["Temperature"].(map[string]interface{})["Reading"].(float64)
-			kpi.TxPower = stats["TxPower"].(map[string]interface{})["Reading"].(float64)
-			kpi.Voltage = stats["Voltage"].(map[string]interface{})["Reading"].(float64)
+		if stats, ok := m["TransceiverStatistics"].(map[string]interface{}); ok {
+			var biasOk, temperatureOk, txPowerOk, voltageOk bool
+			kpi.LaserBiasCurrent, biasOk = importerReading(stats, "BiasCurrent")
+			kpi.Temperature, temperatureOk = importerReading(stats, "Temperature")
+			kpi.TxPower, txPowerOk = importerReading(stats, "TxPower")
+			kpi.Voltage, voltageOk = importerReading(stats, "Voltage")
+			if !biasOk || !temperatureOk || !txPowerOk || !voltageOk {
+				logger.Error("Optical stats (TransceiverStatistics) readings missing [topic=importer]")
+				logger.Debug("Unprocessed Msg: %s", strData)
+				break
+			}
 		} else {
 			logger.Error("Optical stats (TransceiverStatistics) information missing [topic=importer")
 			logger.Debug("Unprocessed Msg: %s", strData)
 			break
 		}
-		kpi.PortId = m["Id"].(string)
+		portID, ok := m["Id"].(string)
+		if !ok {
+			logger.Error("Port (Id) information missing [topic=importer]")
+			logger.Debug("Unprocessed Msg: %s", strData)
+			break
+		}
+		kpi.PortId = portID
 		exportImporterKPI(kpi)
 	case "onos.aaa.stats.kpis":
-		kpi := OnosAaaKPI{}
-		err := json.Unmarshal(data, &kpi)
+		stats, err := splitOnosStats(data)
 		if err != nil {
 			logger.Error("Invalid msg on onos.aaa.stats.kpis: %s, Unprocessed Msg: %s", err.Error(), string(data))
 			break
 		}
-		exportOnosAaaKPI(kpi)
+		for _, raw := range stats {
+			kpi := OnosAaaKPI{}
+			if err := json.Unmarshal(raw, &kpi); err != nil {
+				logger.Error("Invalid msg on onos.aaa.stats.kpis: %s, Unprocessed Msg: %s", err.Error(), string(raw))
+				continue
+			}
+			exportOnosAaaKPI(kpi)
+		}
+	case "onos.dhcp.stats.kpis":
+		stats, err := splitOnosStats(data)
+		if err != nil {
+			logger.Error("Invalid msg on onos.dhcp.stats.kpis: %s, Unprocessed Msg: %s", err.Error(), string(data))
+			break
+		}
+		for _, raw := range stats {
+			kpi := OnosDhcpKPI{}
+			if err := json.Unmarshal(raw, &kpi); err != nil {
+				logger.Error("Invalid msg on onos.dhcp.stats.kpis: %s, Unprocessed Msg: %s", err.Error(), string(raw))
+				continue
+			}
+			exportOnosDhcpKPI(kpi)
+		}
+	case "onos.igmp.stats.kpis":
+		stats, err := splitOnosStats(data)
+		if err != nil {
+			logger.Error("Invalid msg on onos.igmp.stats.kpis: %s, Unprocessed Msg: %s", err.Error(), string(data))
+			break
+		}
+		for _, raw := range stats {
+			kpi := OnosIgmpKPI{}
+			if err := json.Unmarshal(raw, &kpi); err != nil {
+				logger.Error("Invalid msg on onos.igmp.stats.kpis: %s, Unprocessed Msg: %s", err.Error(), string(raw))
+				continue
+			}
+			exportOnosIgmpKPI(kpi)
+		}
+	case "onos.mcast.stats.kpis":
+		stats, err := splitOnosStats(data)
+		if err != nil {
+			logger.Error("Invalid msg on onos.mcast.stats.kpis: %s, Unprocessed Msg: %s", err.Error(), string(data))
+			break
+		}
+		for _, raw := range stats {
+			kpi := OnosMcastKPI{}
+			if err := json.Unmarshal(raw, &kpi); err != nil {
+				logger.Error("Invalid msg on onos.mcast.stats.kpis: %s, Unprocessed Msg: %s", err.Error(), string(raw))
+				continue
+			}
+			exportOnosMcastKPI(kpi)
+		}
+	case "authentication.events":
+		event := OnosAaaAuthEvent{}
+		err := json.Unmarshal(data, &event)
+		if err != nil {
+			logger.Error("Invalid msg on authentication.events: %s, Unprocessed Msg: %s", err.Error(), string(data))
+			break
+		}
+		exportOnosAaaAuthEvent(event)
 	case "bng.stats":
 		kpi := OnosBngKPI{}
 		err := json.Unmarshal(data, &kpi)
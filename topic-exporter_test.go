@@ -0,0 +1,365 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/Shopify/sarama"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/opencord/device-management-interface/go/dmi"
+	"github.com/opencord/voltha-protos/v5/go/voltha"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden exposition files of the export tests")
+
+// fakeConsumerGroupSession stands for the session of a consumer group
+// member, keeping the messages marked as consumed
+type fakeConsumerGroupSession struct {
+	sync.Mutex
+	marked []*sarama.ConsumerMessage
+}
+
+func (s *fakeConsumerGroupSession) Claims() map[string][]int32               { return nil }
+func (s *fakeConsumerGroupSession) MemberID() string                         { return "test" }
+func (s *fakeConsumerGroupSession) GenerationID() int32                      { return 1 }
+func (s *fakeConsumerGroupSession) MarkOffset(string, int32, int64, string)  {}
+func (s *fakeConsumerGroupSession) Commit()                                  {}
+func (s *fakeConsumerGroupSession) ResetOffset(string, int32, int64, string) {}
+func (s *fakeConsumerGroupSession) Context() context.Context                 { return context.Background() }
+func (s *fakeConsumerGroupSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.Lock()
+	defer s.Unlock()
+	s.marked = append(s.marked, msg)
+}
+
+// fakeConsumerGroupClaim is a claim on a partition holding the given messages
+type fakeConsumerGroupClaim struct {
+	topic    string
+	messages chan *sarama.ConsumerMessage
+}
+
+func newFakeConsumerGroupClaim(topic string, values [][]byte) *fakeConsumerGroupClaim {
+	claim := &fakeConsumerGroupClaim{topic: topic, messages: make(chan *sarama.ConsumerMessage, len(values))}
+	for offset, value := range values {
+		claim.messages <- &sarama.ConsumerMessage{Topic: topic, Offset: int64(offset), Value: value}
+	}
+	close(claim.messages)
+	return claim
+}
+
+func (c *fakeConsumerGroupClaim) Topic() string                            { return c.topic }
+func (c *fakeConsumerGroupClaim) Partition() int32                         { return 0 }
+func (c *fakeConsumerGroupClaim) InitialOffset() int64                     { return 0 }
+func (c *fakeConsumerGroupClaim) HighWaterMarkOffset() int64               { return int64(cap(c.messages)) }
+func (c *fakeConsumerGroupClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+// fakeClusterAdmin answers CreateTopic with the given error, the other
+// methods are not used
+type fakeClusterAdmin struct {
+	sarama.ClusterAdmin
+	created map[string]*sarama.TopicDetail
+	err     error
+}
+
+func (a *fakeClusterAdmin) CreateTopic(topic string, detail *sarama.TopicDetail, validateOnly bool) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.created[topic] = detail
+	return nil
+}
+
+// newTestRegistry registers the metrics on a registry of their own, with
+// every series of the previous tests removed
+func newTestRegistry() *prometheus.Registry {
+	metricFamilies.RLock()
+	for _, vec := range metricFamilies.vecs {
+		switch prom := vec.prom.(type) {
+		case *prometheus.GaugeVec:
+			prom.Reset()
+		case *prometheus.CounterVec:
+			prom.Reset()
+		case *prometheus.HistogramVec:
+			prom.Reset()
+		}
+	}
+	metricFamilies.RUnlock()
+
+	// the handlers keeping state across messages
+	bngSessions.Lock()
+	bngSessions.sessions = make(map[string]*bngSession)
+	bngSessions.Unlock()
+	onosAaaSubscribers.Lock()
+	onosAaaSubscribers.subscribers = make(map[string]*onosAaaSubscriber)
+	onosAaaSubscribers.Unlock()
+	volthaUniInfoLabels.Lock()
+	volthaUniInfoLabels.labels = make(map[string][]string)
+	volthaUniInfoLabels.Unlock()
+
+	registry := prometheus.NewRegistry()
+	registerMetrics(registry)
+	return registry
+}
+
+// assertGolden compares the exposition of the registry with
+// testdata/export/<name>.prom
+func assertGolden(t *testing.T, registry *prometheus.Registry, name string) {
+	golden := filepath.Join("testdata", "export", name+".prom")
+	if *updateGolden {
+		var exposition bytes.Buffer
+		assert.NoError(t, dumpMetrics(&exposition, registry))
+		assert.NoError(t, ioutil.WriteFile(golden, exposition.Bytes(), 0644))
+	}
+	expected, err := os.Open(golden)
+	assert.NoError(t, err)
+	defer expected.Close()
+	assert.NoError(t, testutil.GatherAndCompare(registry, expected))
+}
+
+func mustMarshal(t *testing.T, message proto.Message) []byte {
+	data, err := proto.Marshal(message)
+	assert.NoError(t, err)
+	return data
+}
+
+func volthaKpiEvent(t *testing.T, slices ...*voltha.MetricInformation) []byte {
+	return mustMarshal(t, &voltha.Event{
+		Header: &voltha.EventHeader{Type: voltha.EventType_KPI_EVENT2},
+		EventType: &voltha.Event_KpiEvent2{KpiEvent2: &voltha.KpiEvent2{
+			Type:      voltha.KpiEventType_slice,
+			Ts:        1636106400,
+			SliceData: slices,
+		}},
+	})
+}
+
+func TestExportTopics(t *testing.T) {
+	logger.Setup("", "ERROR")
+
+	tests := []struct {
+		name     string
+		topic    string
+		messages [][]byte
+	}{
+		{
+			name:  "voltha-olt",
+			topic: "voltha.events",
+			messages: [][]byte{volthaKpiEvent(t, &voltha.MetricInformation{
+				Metadata: &voltha.MetricMetaData{
+					Title:           "ETHERNET_NNI",
+					LogicalDeviceId: "of:0000000000000001",
+					SerialNo:        "BBSIM_OLT_0",
+					DeviceId:        "olt-1",
+					Context:         map[string]string{"portno": "1048576"},
+				},
+				Metrics: map[string]float32{"TxBytes": 1024, "RxBytes": 2048, "TxPackets": 8, "RxPackets": 16},
+			})},
+		},
+		{
+			name:  "voltha-onu",
+			topic: "voltha.events",
+			messages: [][]byte{volthaKpiEvent(t, &voltha.MetricInformation{
+				Metadata: &voltha.MetricMetaData{
+					Title:           "PON_Optical",
+					LogicalDeviceId: "of:0000000000000001",
+					SerialNo:        "BBSM00000001",
+					DeviceId:        "onu-1",
+					Context:         map[string]string{"intf_id": "0", "portno": "16"},
+				},
+				Metrics: map[string]float32{"transmit_power": 2.5, "receive_power": -18.5},
+			})},
+		},
+		{
+			name:  "voltha-uni-status",
+			topic: "voltha.events",
+			messages: [][]byte{
+				volthaKpiEvent(t, &voltha.MetricInformation{
+					Metadata: &voltha.MetricMetaData{
+						Title:           "UNI_Status",
+						LogicalDeviceId: "of:0000000000000001",
+						SerialNo:        "BBSM00000001",
+						DeviceId:        "onu-1",
+						Context:         map[string]string{"uni_port_no": "16", "entity_id": "257"},
+					},
+					Metrics: map[string]float32{"oper_status": 0, "uni_admin_state": 1, "ethernet_type": 3},
+				}),
+				// an unknown state leaves the series alone
+				volthaKpiEvent(t, &voltha.MetricInformation{
+					Metadata: &voltha.MetricMetaData{
+						Title:           "UNI_Status",
+						LogicalDeviceId: "of:0000000000000001",
+						SerialNo:        "BBSM00000001",
+						DeviceId:        "onu-1",
+						Context:         map[string]string{"uni_port_no": "16", "entity_id": "257"},
+					},
+					Metrics: map[string]float32{"oper_status": 5, "uni_admin_state": 2, "ethernet_type": 3},
+				}),
+			},
+		},
+		{
+			name:     "onos-kpis",
+			topic:    "onos.kpis",
+			messages: [][]byte{[]byte(`{"deviceId":"of:0000000000000001","ports":[{"portId":"16","pktRx":1,"pktTx":2,"bytesRx":64,"bytesTx":128,"pktRxDrp":0,"pktTxDrp":3}]}`)},
+		},
+		{
+			name:  "onos-events",
+			topic: "onos.events",
+			messages: [][]byte{
+				[]byte(`{"type":"PORT_ADDED","deviceId":"of:0000000000000001","port":{"portId":"16","isEnabled":true,"type":"FIBER","portSpeed":1000,"annotations":{"portName":"BBSM00000001-1"}}}`),
+				[]byte(`{"type":"PORT_ADDED","deviceId":"of:0000000000000001","port":{"portId":"17","isEnabled":false,"type":"FIBER","portSpeed":1000,"annotations":{"portName":"BBSM00000001-2"}}}`),
+				[]byte(`{"type":"PORT_REMOVED","deviceId":"of:0000000000000001","port":{"portId":"17"}}`),
+			},
+		},
+		{
+			name:     "importer",
+			topic:    "importer",
+			messages: [][]byte{[]byte(`/redfish {"Id":"eth0","TransceiverStatistics":{"BiasCurrent":{"Reading":6.5},"Temperature":{"Reading":41},"TxPower":{"Reading":1.5},"Voltage":{"Reading":3.3}}}`)},
+		},
+		{
+			name:     "onos-aaa-stats",
+			topic:    "onos.aaa.stats.kpis",
+			messages: [][]byte{[]byte(`[{"acceptResponsesRx":5,"requestRttMillis":20},{"instanceId":"onos-1","deviceId":"of:0000000000000001","portNumber":"16","acceptResponsesRx":1,"requestRttMillis":7}]`)},
+		},
+		{
+			name:     "onos-dhcp-stats",
+			topic:    "onos.dhcp.stats.kpis",
+			messages: [][]byte{[]byte(`{"instanceId":"onos-1","deviceId":"of:0000000000000001","portNumber":"16","dhcpDiscover":1,"dhcpOffer":1,"dhcpRequest":1,"dhcpAck":1}`)},
+		},
+		{
+			name:     "onos-igmp-stats",
+			topic:    "onos.igmp.stats.kpis",
+			messages: [][]byte{[]byte(`{"igmpJoinReq":3,"igmpLeaveReq":1,"invalidIgmpMsgReceived":2}`)},
+		},
+		{
+			name:     "onos-mcast-stats",
+			topic:    "onos.mcast.stats.kpis",
+			messages: [][]byte{[]byte(`{"instanceId":"onos-1","activeGroups":2,"sinks":5}`)},
+		},
+		{
+			name:  "authentication-events",
+			topic: "authentication.events",
+			messages: [][]byte{
+				[]byte(`{"timestamp":1636106400000,"deviceId":"of:0000000000000001","portNumber":"16","serialNumber":"BBSM00000001-1","supplicantMacAddress":"2e:60:00:00:00:01","authenticationState":"STARTED"}`),
+				[]byte(`{"timestamp":1636106401000,"deviceId":"of:0000000000000001","portNumber":"16","serialNumber":"BBSM00000001-1","supplicantMacAddress":"2e:60:00:00:00:01","authenticationState":"APPROVED"}`),
+			},
+		},
+		{
+			name:  "authentication-logoff",
+			topic: "authentication.events",
+			messages: [][]byte{
+				[]byte(`{"timestamp":1636106400000,"deviceId":"of:0000000000000001","portNumber":"16","serialNumber":"BBSM00000001-1","supplicantMacAddress":"2e:60:00:00:00:01","authenticationState":"APPROVED"}`),
+				[]byte(`{"timestamp":1636106401000,"deviceId":"of:0000000000000001","portNumber":"16","serialNumber":"BBSM00000001-1","supplicantMacAddress":"2e:60:00:00:00:01","authenticationState":"LOGOFF"}`),
+			},
+		},
+		{
+			name:     "bng-stats",
+			topic:    "bng.stats",
+			messages: [][]byte{[]byte(`{"macAddress":"2e:60:00:00:00:01","ipAddress":"10.0.0.2","pppoeSessionId":7,"attachmentType":"PPPoE","sTag":900,"cTag":901,"onuSerialNumber":"BBSM00000001","deviceId":"of:0000000000000001","portNumber":"16","upTxBytes":100,"downRxBytes":200,"timestamp":"2021-11-05T10:00:00Z"}`)},
+		},
+		{
+			name:  "dm-metrics",
+			topic: "dm.metrics",
+			messages: [][]byte{mustMarshal(t, &dmi.Metric{
+				MetricId: dmi.MetricNames_METRIC_CPU_TEMP,
+				MetricMetadata: &dmi.MetricMetaData{
+					DeviceUuid:    &dmi.Uuid{Uuid: "5a23ee76-a5d8-11eb-b2e2-0242ac110002"},
+					ComponentUuid: &dmi.Uuid{Uuid: "7b3c9e10-a5d8-11eb-b2e2-0242ac110002"},
+					ComponentName: "cpu-0",
+				},
+				Value: &dmi.ComponentSensorData{Value: 52, Timestamp: &timestamp.Timestamp{Seconds: 1636106400}},
+			})},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry := newTestRegistry()
+			session := &fakeConsumerGroupSession{}
+			consumer := &Consumer{HandleFunc: export}
+
+			assert.NoError(t, consumer.ConsumeClaim(session, newFakeConsumerGroupClaim(test.topic, test.messages)))
+			assert.Len(t, session.marked, len(test.messages))
+			assertGolden(t, registry, test.name)
+		})
+	}
+}
+
+func TestExportMalformed(t *testing.T) {
+	logger.Setup("", "ERROR")
+	registry := newTestRegistry()
+	session := &fakeConsumerGroupSession{}
+	consumer := &Consumer{HandleFunc: export}
+
+	for _, topic := range malformedTopics {
+		assert.NoError(t, consumer.ConsumeClaim(session, newFakeConsumerGroupClaim(topic, malformedMessages)))
+	}
+	// the malformed messages are consumed all the same
+	assert.Len(t, session.marked, len(malformedTopics)*len(malformedMessages))
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader("")))
+}
+
+// malformedTopics are fed every malformedMessages entry
+var malformedTopics = []string{
+	"voltha.events", "onos.kpis", "onos.events", "importer",
+	"onos.aaa.stats.kpis", "onos.dhcp.stats.kpis", "onos.igmp.stats.kpis", "onos.mcast.stats.kpis",
+	"authentication.events", "bng.stats", "dm.metrics", "unknown.topic",
+}
+
+var malformedMessages = [][]byte{
+	nil,
+	[]byte("not json nor protobuf"),
+	[]byte(`{"truncated":`),
+	[]byte(`[1, 2]`),
+	// valid JSON with fields of the wrong type
+	[]byte(`{"deviceId":7,"macAddress":7,"Id":7,"TransceiverStatistics":{"BiasCurrent":"none"}}`),
+	[]byte(`/redfish {"Id":"eth0","TransceiverStatistics":{"BiasCurrent":{"Reading":"high"}}}`),
+	{0xff, 0xff, 0xff, 0xff},
+}
+
+func TestConsumeClaimWithoutHandler(t *testing.T) {
+	logger.Setup("", "ERROR")
+	consumer := &Consumer{}
+	err := consumer.ConsumeClaim(&fakeConsumerGroupSession{}, newFakeConsumerGroupClaim("onos.kpis", nil))
+	assert.Error(t, err)
+}
+
+func TestCreateTopic(t *testing.T) {
+	admin := &fakeClusterAdmin{created: map[string]*sarama.TopicDetail{}}
+	assert.NoError(t, createTopic(admin, "onos.kpis", 3, 2))
+	assert.Equal(t, int32(3), admin.created["onos.kpis"].NumPartitions)
+	assert.Equal(t, int16(2), admin.created["onos.kpis"].ReplicationFactor)
+
+	// an existing topic is fine
+	admin.err = &sarama.TopicError{Err: sarama.ErrTopicAlreadyExists}
+	assert.NoError(t, createTopic(admin, "onos.kpis", 3, 2))
+
+	admin.err = errors.New("broker unreachable")
+	assert.Error(t, createTopic(admin, "onos.kpis", 3, 2))
+}
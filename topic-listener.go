@@ -21,6 +21,7 @@ import (
 	"os"
 	"os/signal"
 	"sync"
+	"syscall"
 
 	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
 	"github.com/Shopify/sarama"
@@ -29,26 +30,39 @@ import (
 // Consumer represents a Sarama consumer group consumer
 type Consumer struct {
 	HandleFunc func(topic *string, data []byte)
+	// HandleMessage, when set, is given the whole message instead, with its
+	// key, partition, offset and timestamp
+	// It reports whether the message was handled, the offsets of the
+	// others are not committed.
+	HandleMessage func(message *sarama.ConsumerMessage) bool
 }
 
-
-func topicListener(ctx context.Context, topics []string, consGrp sarama.ConsumerGroup, wg sync.WaitGroup) {
+// topicListener consumes the topics until the context is cancelled or the
+// process interrupted. It is done once the consumer group session is over,
+// no message being handled nor marked after.
+func topicListener(ctx context.Context, topics []string, consGrp sarama.ConsumerGroup, wg *sync.WaitGroup, consumer *Consumer) {
 	logger.Info("Starting topicListener for [%s]", topics)
 	defer wg.Done()
 
-	/**
-	 * Setup a new Sarama consumer group
-	 */
-	consumer := Consumer{
-		HandleFunc: export,
-	}
+	ctx, cancel := context.WithCancel(ctx)
+	consuming := make(chan struct{})
+	defer func() {
+		cancel()
+		<-consuming
+	}()
 
 	go func() {
+		defer close(consuming)
 		for {
 			// `Consume` should be called inside an infinite loop, when a
 			// server-side rebalance happens, the consumer session will need to be
 			// recreated to get the new claims
-			if err := consGrp.Consume(ctx, topics, &consumer); err != nil {
+			err := consGrp.Consume(ctx, topics, consumer)
+			// the context is cancelled once the listener is done
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
 				log.Panicf("Error from consumer: %v", err)
 			}
 
@@ -56,7 +70,7 @@ func topicListener(ctx context.Context, topics []string, consGrp sarama.Consumer
 	}()
 
 	signals := make(chan os.Signal, 1)
-	signal.Notify(signals, os.Interrupt)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
 
 	for {
 		select {
@@ -88,12 +102,18 @@ func (consumer *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 	// The `ConsumeClaim` itself is called within a goroutine, see:
 	// https://github.com/Shopify/sarama/blob/main/consumer_group.go#L27-L29
 
-	if consumer.HandleFunc == nil {
+	if consumer.HandleFunc == nil && consumer.HandleMessage == nil {
 		logger.Error("No handler for consumer ")
 		return fmt.Errorf("no handler for consumer")
 	}
 
 	for message := range claim.Messages() {
+		if consumer.HandleMessage != nil {
+			if consumer.HandleMessage(message) {
+				session.MarkMessage(message, "")
+			}
+			continue
+		}
 		topic := string(message.Topic)
 		consumer.HandleFunc(&topic, message.Value)
 		session.MarkMessage(message, "")
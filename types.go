@@ -14,37 +14,181 @@
 
 package main
 
+import "time"
+
 // configuration
 type BrokerInfo struct {
-	Name        string   `yaml: name`
-	Host        string   `yaml: host`
-	Description string   `yaml: description`
-	Partitions  int      `yaml: partitions`
-	Replicas    int      `yaml: replicas`
-	Topics      []string `yaml: topics`
+	Name        string   `yaml:"name"`
+	Host        string   `yaml:"host"`
+	Description string   `yaml:"description"`
+	Partitions  int      `yaml:"partitions"`
+	Replicas    int      `yaml:"replicas"`
+	Topics      []string `yaml:"topics"`
 }
 
 type LoggerInfo struct {
-	LogLevel string `yaml: loglevel`
-	Host     string `yaml: host`
+	LogLevel string `yaml:"loglevel"`
+	Host     string `yaml:"host"`
+}
+
+type BasicAuthInfo struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+type RemoteWriteInfo struct {
+	Enabled bool   `yaml:"enabled"`
+	Url     string `yaml:"url"`
+	// how often the series are pushed
+	Interval time.Duration `yaml:"interval"`
+	Timeout  time.Duration `yaml:"timeout"`
+	// samples per request and samples kept in memory when the endpoint
+	// cannot keep up, the oldest are dropped first
+	BatchSize    int               `yaml:"batch_size"`
+	QueueSize    int               `yaml:"queue_size"`
+	MaxRetries   int               `yaml:"max_retries"`
+	RetryBackoff time.Duration     `yaml:"retry_backoff"`
+	Headers      map[string]string `yaml:"headers"`
+	BasicAuth    BasicAuthInfo     `yaml:"basic_auth"`
+	BearerToken  string            `yaml:"bearer_token"`
+}
+
+type OtlpInfo struct {
+	Enabled bool `yaml:"enabled"`
+	// grpc (default) or http
+	Protocol string `yaml:"protocol"`
+	// host:port for gRPC, URL of the metrics path for HTTP
+	Endpoint string `yaml:"endpoint"`
+	// plain text gRPC instead of TLS
+	Insecure bool              `yaml:"insecure"`
+	Headers  map[string]string `yaml:"headers"`
+	Interval time.Duration     `yaml:"interval"`
+	Timeout  time.Duration     `yaml:"timeout"`
+	// labels exported as resource attributes, the device identity by default
+	ResourceLabels []string `yaml:"resource_labels"`
+}
+
+type InfluxInfo struct {
+	Enabled bool `yaml:"enabled"`
+	// write URL, e.g. http://influxdb:8086/api/v2/write?org=o&bucket=b&precision=ns
+	Url   string `yaml:"url"`
+	Token string `yaml:"token"`
+	// local file the lines are appended to instead, for testing
+	File          string        `yaml:"file"`
+	BatchSize     int           `yaml:"batch_size"`
+	FlushInterval time.Duration `yaml:"flush_interval"`
+	Timeout       time.Duration `yaml:"timeout"`
+}
+
+type KafkaOutputInfo struct {
+	Enabled bool `yaml:"enabled"`
+	// defaults to the broker the topics are consumed from
+	Host  string `yaml:"host"`
+	Topic string `yaml:"topic"`
+	// json or protobuf
+	Format     string `yaml:"format"`
+	Partitions int    `yaml:"partitions"`
+	Replicas   int    `yaml:"replicas"`
+}
+
+type LegacyPushInfo struct {
+	Enabled bool `yaml:"enabled"`
+	// host:port of the Graphite plaintext or StatsD endpoint
+	Address string `yaml:"address"`
+	// tcp or udp
+	Protocol string `yaml:"protocol"`
+	// dotted path of a series, {name} is the metric name and {<label>}
+	// the value of a label, e.g. kte.{serial_number}.{name}, the other
+	// labels are appended as <label>.<value> unless sent as tags
+	Template string `yaml:"template"`
+	// send the labels the template does not consume as tags
+	Tags     bool          `yaml:"tags"`
+	Interval time.Duration `yaml:"interval"`
+	Timeout  time.Duration `yaml:"timeout"`
 }
 
 type TargetInfo struct {
-	Type        string `yaml: type`
-	Name        string `yaml: name`
-	Port        int    `yaml: port`
-	Description string `yaml: description`
+	Type        string `yaml:"type"`
+	Name        string `yaml:"name"`
+	Port        int    `yaml:"port"`
+	Description string `yaml:"description"`
+	// Go runtime and process metrics, e.g. go_goroutines, off by default
+	GoCollector      bool `yaml:"go_collector"`
+	ProcessCollector bool `yaml:"process_collector"`
+	// prefix of every family and labels added to every series, for the
+	// exporters of several deployments to share a Prometheus
+	Namespace   string            `yaml:"namespace"`
+	ConstLabels map[string]string `yaml:"const_labels"`
+	// push outputs, besides the scrape endpoint
+	RemoteWrite RemoteWriteInfo `yaml:"remote_write"`
+	Otlp        OtlpInfo        `yaml:"otlp"`
+	Influx      InfluxInfo      `yaml:"influxdb"`
+	KafkaOutput KafkaOutputInfo `yaml:"kafka_output"`
+	Graphite    LegacyPushInfo  `yaml:"graphite"`
+	Statsd      LegacyPushInfo  `yaml:"statsd"`
 }
 
 type ConvInfo struct {
-	Onusnhex bool `yaml: onusnhex`
+	Onusnhex bool `yaml:"onusnhex"`
+}
+
+type VolthaInfo struct {
+	// KpiEvent2 titles without a dedicated handler that are exported as
+	// voltha_<title>_<metric>
+	Passthrough []PassthroughInfo `yaml:"passthrough"`
+}
+
+type PassthroughInfo struct {
+	// "*" allows the titles that are not listed
+	Title string `yaml:"title"`
+	// the context entries exported as labels, the others are ignored
+	Context []string `yaml:"context"`
+}
+
+type AggregationInfo struct {
+	Enabled bool `yaml:"enabled"`
+	PerOlt  bool `yaml:"per_olt"`
+	PerPon  bool `yaml:"per_pon"`
+	// ONUs that did not report for this long are left out of the aggregates
+	StaleAfter time.Duration `yaml:"stale_after"`
+}
+
+type InventoryInfo struct {
+	Enabled bool `yaml:"enabled"`
+	// optional static inventory, a YAML list of devices or a CSV file
+	File string `yaml:"file"`
+	// labels of the static inventory entries added to kte_device_info
+	ExtraLabels []string `yaml:"extra_labels"`
+}
+
+type SadisInfo struct {
+	Enabled bool `yaml:"enabled"`
+	// file path or HTTP URL of the SADIS subscriber entries
+	Url     string        `yaml:"url"`
+	Refresh time.Duration `yaml:"refresh"`
+}
+
+// RelabelRule is a Prometheus style metric relabel rule
+type RelabelRule struct {
+	SourceLabels []string `yaml:"source_labels"`
+	Separator    string   `yaml:"separator"`
+	Regex        string   `yaml:"regex"`
+	TargetLabel  string   `yaml:"target_label"`
+	Replacement  string   `yaml:"replacement"`
+	// replace (default), keep, drop, labeldrop or labelkeep
+	Action string `yaml:"action"`
 }
 
 type Config struct {
-	Broker BrokerInfo `yaml: broker`
-	Logger LoggerInfo `yaml: logger`
-	Target TargetInfo `yaml: "target"`
-	Conv   ConvInfo   `yaml: "conv"`
+	Broker      BrokerInfo      `yaml:"broker"`
+	Logger      LoggerInfo      `yaml:"logger"`
+	Target      TargetInfo      `yaml:"target"`
+	Conv        ConvInfo        `yaml:"conv"`
+	Voltha      VolthaInfo      `yaml:"voltha"`
+	Aggregation AggregationInfo `yaml:"aggregation"`
+	Inventory   InventoryInfo   `yaml:"inventory"`
+	Sadis       SadisInfo       `yaml:"sadis"`
+	Relabel     []RelabelRule   `yaml:"relabel"`
 }
 
 // KPI Events format
@@ -114,8 +258,25 @@ type OnosPort struct {
 }
 
 type OnosKPI struct {
-	DeviceID string      `json:"deviceId"`
-	Ports    []*OnosPort `json:"ports"`
+	DeviceID  string        `json:"deviceId"`
+	Ports     []*OnosPort   `json:"ports"`
+	Timestamp onosTimestamp `json:"timestamp"`
+}
+
+// ONOS device and port events
+type OnosEventPort struct {
+	PortID      string            `json:"portId"`
+	Enabled     bool              `json:"isEnabled"`
+	Type        string            `json:"type"`
+	Speed       float64           `json:"portSpeed"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type OnosEvent struct {
+	Type      string         `json:"type"`
+	DeviceID  string         `json:"deviceId"`
+	Port      *OnosEventPort `json:"port,omitempty"`
+	Timestamp onosTimestamp  `json:"timestamp"`
 }
 
 type ImporterKPI struct {
@@ -127,6 +288,10 @@ type ImporterKPI struct {
 }
 
 type OnosAaaKPI struct {
+	// per device/port statistics carry the ONOS instance, device and port
+	InstanceID            string  `json:"instanceId,omitempty"`
+	DeviceID              string  `json:"deviceId,omitempty"`
+	PortNumber            string  `json:"portNumber,omitempty"`
 	RxAcceptResponses     float64 `json:"acceptResponsesRx"`
 	RxRejectResponses     float64 `json:"rejectResponsesRx"`
 	RxChallengeResponses  float64 `json:"challengeResponsesRx"`
@@ -157,6 +322,53 @@ type OnosAaaKPI struct {
 	RxResIdEapFrames      float64 `json:"resIdEapFramesRx"`
 }
 
+// ONOS DHCP L2 relay statistics
+type OnosDhcpKPI struct {
+	InstanceID string  `json:"instanceId,omitempty"`
+	DeviceID   string  `json:"deviceId,omitempty"`
+	PortNumber string  `json:"portNumber,omitempty"`
+	Discover   float64 `json:"dhcpDiscover"`
+	Offer      float64 `json:"dhcpOffer"`
+	Request    float64 `json:"dhcpRequest"`
+	Ack        float64 `json:"dhcpAck"`
+	Nak        float64 `json:"dhcpNak"`
+	Decline    float64 `json:"dhcpDecline"`
+	Release    float64 `json:"dhcpRelease"`
+	Inform     float64 `json:"dhcpInform"`
+}
+
+// ONOS IGMP proxy statistics
+type OnosIgmpKPI struct {
+	InstanceID         string  `json:"instanceId,omitempty"`
+	DeviceID           string  `json:"deviceId,omitempty"`
+	PortNumber         string  `json:"portNumber,omitempty"`
+	Join               float64 `json:"igmpJoinReq"`
+	Leave              float64 `json:"igmpLeaveReq"`
+	GeneralQuery       float64 `json:"igmpGeneralMembershipQuery"`
+	GroupSpecificQuery float64 `json:"igmpGrpSpecificMembershipQuery"`
+	MembershipReport   float64 `json:"igmpMembershipReport"`
+	InvalidPackets     float64 `json:"invalidIgmpMsgReceived"`
+}
+
+// ONOS olt/mcast statistics
+type OnosMcastKPI struct {
+	InstanceID   string  `json:"instanceId,omitempty"`
+	DeviceID     string  `json:"deviceId,omitempty"`
+	PortNumber   string  `json:"portNumber,omitempty"`
+	ActiveGroups float64 `json:"activeGroups"`
+	Sinks        float64 `json:"sinks"`
+}
+
+// ONOS AAA per supplicant authentication event
+type OnosAaaAuthEvent struct {
+	Timestamp     int64  `json:"timestamp"`
+	DeviceID      string `json:"deviceId"`
+	PortNumber    string `json:"portNumber"`
+	SerialNumber  string `json:"serialNumber"`
+	SupplicantMac string `json:"supplicantMacAddress"`
+	State         string `json:"authenticationState"`
+}
+
 type OnosBngKPI struct {
 	Mac             string   `json:"macAddress"`
 	Ip              string   `json:"ipAddress"`
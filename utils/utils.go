@@ -20,6 +20,15 @@ import (
 	"strings"
 )
 
+// OMCI encodings of the ANI-G and ONU-G test results (ITU-T G.988)
+const (
+	omciVoltageResolution      = 0.02      // 20 mV
+	omciOpticalPowerResolution = 0.002     // 0.002 dBuW
+	omciBiasCurrentResolution  = 0.002     // 2 uA, in mA
+	omciTemperatureResolution  = 1.0 / 256 // 1/256 degree C
+	dBuWTodBm                  = 30.0
+)
+
 var OnuSNhex bool
 
 //OnuSnHexEncode converts ONU sn from human readable format like 'SCOM00001B6D' to hex like '53434F4D00001B6D'
@@ -40,3 +49,57 @@ func GetOnuSN(onuSN string) (string) {
                 return onuSN
         }
 }
+
+// omciSigned interprets an OMCI 2 bytes field as a two's complement value
+func omciSigned(raw float64) float64 {
+	return float64(int16(uint16(int64(raw))))
+}
+
+//OmciPowerFeedVoltage converts an OMCI power feed voltage to Volts
+func OmciPowerFeedVoltage(raw float64) float64 {
+	return omciSigned(raw) * omciVoltageResolution
+}
+
+//OmciOpticalPower converts an OMCI received or launched optical power to dBm
+func OmciOpticalPower(raw float64) float64 {
+	return omciSigned(raw)*omciOpticalPowerResolution - dBuWTodBm
+}
+
+//OmciLaserBiasCurrent converts an OMCI laser bias current to mA
+func OmciLaserBiasCurrent(raw float64) float64 {
+	return float64(uint16(int64(raw))) * omciBiasCurrentResolution
+}
+
+//OmciTemperature converts an OMCI temperature to degrees Celsius
+func OmciTemperature(raw float64) float64 {
+	return omciSigned(raw) * omciTemperatureResolution
+}
+
+// VOLTHA openolt port number encoding
+const (
+	nniPortNoBase   = 1 << 20
+	ponPortNoBase   = 2 << 28
+	uniPortNoIntfID = 0xFF000
+	bitsForOnuUniID = 12
+)
+
+//NniIntfIDFromPortNo returns the interface id of an OLT NNI port number like 1048576
+func NniIntfIDFromPortNo(portNo uint32) (uint32, bool) {
+	if portNo&nniPortNoBase == 0 || portNo&ponPortNoBase != 0 {
+		return 0, false
+	}
+	return portNo ^ nniPortNoBase, true
+}
+
+//PonIntfIDFromPortNo returns the interface id of an OLT PON port number like 536870912
+func PonIntfIDFromPortNo(portNo uint32) (uint32, bool) {
+	if portNo&ponPortNoBase == 0 {
+		return 0, false
+	}
+	return portNo ^ ponPortNoBase, true
+}
+
+//PonIntfIDFromUniPortNo returns the PON interface id an ONU UNI port number belongs to
+func PonIntfIDFromUniPortNo(portNo uint32) uint32 {
+	return (portNo & uniPortNoIntfID) >> bitsForOnuUniID
+}
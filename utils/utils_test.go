@@ -18,6 +18,7 @@ package utils
 
 import (
 	"github.com/stretchr/testify/assert"
+	"strconv"
 	"testing"
 )
 
@@ -49,3 +50,99 @@ func TestGetOnuSN(t *testing.T) {
 }
 
 
+
+func TestOmciConversions(t *testing.T) {
+	testCases := []struct {
+		name           string
+		convert        func(float64) float64
+		input          float64
+		expectedOutput float64
+	}{
+		{
+			name:           "voltage",
+			convert:        OmciPowerFeedVoltage,
+			input:          165,
+			expectedOutput: 3.3,
+		},
+		{
+			name:           "optical power",
+			convert:        OmciOpticalPower,
+			input:          5000,
+			expectedOutput: -20,
+		},
+		{
+			name:           "negative optical power",
+			convert:        OmciOpticalPower,
+			input:          0xFC18,
+			expectedOutput: -32,
+		},
+		{
+			name:           "bias current",
+			convert:        OmciLaserBiasCurrent,
+			input:          7500,
+			expectedOutput: 15,
+		},
+		{
+			name:           "temperature",
+			convert:        OmciTemperature,
+			input:          0x2980,
+			expectedOutput: 41.5,
+		},
+		{
+			name:           "negative temperature",
+			convert:        OmciTemperature,
+			input:          0xFB00,
+			expectedOutput: -5,
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.InDelta(t, testCase.expectedOutput, testCase.convert(testCase.input), 1e-9, testCase.name)
+	}
+}
+
+func TestPortNoConversions(t *testing.T) {
+	testCases := []struct {
+		portNo      uint32
+		expectedNni string
+		expectedPon string
+		expectedUni uint32
+	}{
+		{
+			portNo:      1048576,
+			expectedNni: "0",
+			expectedPon: "NA",
+		},
+		{
+			portNo:      1048578,
+			expectedNni: "2",
+			expectedPon: "NA",
+		},
+		{
+			portNo:      536870915,
+			expectedNni: "NA",
+			expectedPon: "3",
+		},
+		{
+			portNo:      0x3011,
+			expectedNni: "NA",
+			expectedPon: "NA",
+			expectedUni: 3,
+		},
+	}
+
+	format := func(intfID uint32, ok bool) string {
+		if !ok {
+			return "NA"
+		}
+		return strconv.Itoa(int(intfID))
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expectedNni, format(NniIntfIDFromPortNo(testCase.portNo)))
+		assert.Equal(t, testCase.expectedPon, format(PonIntfIDFromPortNo(testCase.portNo)))
+		if testCase.expectedUni != 0 {
+			assert.Equal(t, testCase.expectedUni, PonIntfIDFromUniPortNo(testCase.portNo))
+		}
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/opencord/voltha-protos/v5/go/voltha"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const cDefaultAggregationStaleAfter = 15 * time.Minute
+
+// volthaOnuSample holds what an ONU last reported, as needed by the aggregates
+type volthaOnuSample struct {
+	oltID string
+	ponID string
+	// octets counted per bridge port and direction. Like the per-ONU
+	// families, the Ethernet_Bridge_Port_History values are taken as the
+	// counts of the interval reported.
+	octets           map[volthaBridgePortKey]float64
+	fecUncorrectable float64
+	rxPower          float64
+	hasRxPower       bool
+	lastSeen         time.Time
+}
+
+type volthaBridgePortKey struct {
+	portNo   string
+	upstream bool
+}
+
+// volthaAggregator computes the per-OLT and per-PON aggregates of the ONU
+// metrics at scrape time
+type volthaAggregator struct {
+	sync.Mutex
+	perOlt     bool
+	perPon     bool
+	staleAfter time.Duration
+	onus       map[string]*volthaOnuSample
+
+	ponRxBytes          *prometheus.Desc
+	ponTxBytes          *prometheus.Desc
+	ponFecUncorrectable *prometheus.Desc
+	ponOnusReporting    *prometheus.Desc
+	ponRxPowerMin       *prometheus.Desc
+	ponRxPowerAvg       *prometheus.Desc
+	ponRxPowerMax       *prometheus.Desc
+	oltRxBytes          *prometheus.Desc
+	oltTxBytes          *prometheus.Desc
+	oltFecUncorrectable *prometheus.Desc
+	oltOnusReporting    *prometheus.Desc
+}
+
+// volthaAggregates is nil unless aggregation is enabled in the configuration
+var volthaAggregates *volthaAggregator
+
+func newVolthaAggregator(conf AggregationInfo) *volthaAggregator {
+	if conf.StaleAfter == 0 {
+		conf.StaleAfter = cDefaultAggregationStaleAfter
+	}
+	ponLabels := []string{"logical_device_id", "pon_id"}
+	oltLabels := []string{"logical_device_id"}
+
+	return &volthaAggregator{
+		perOlt:     conf.PerOlt,
+		perPon:     conf.PerPon,
+		staleAfter: conf.StaleAfter,
+		onus:       make(map[string]*volthaOnuSample),
+
+		ponRxBytes:          prometheus.NewDesc("voltha_pon_onu_rx_bytes_total", "Sum of the bytes received by the ONUs of the PON", ponLabels, nil),
+		ponTxBytes:          prometheus.NewDesc("voltha_pon_onu_tx_bytes_total", "Sum of the bytes transmitted by the ONUs of the PON", ponLabels, nil),
+		ponFecUncorrectable: prometheus.NewDesc("voltha_pon_onu_fec_uncorrectable_words", "Sum of the FEC uncorrectable words of the ONUs of the PON", ponLabels, nil),
+		ponOnusReporting:    prometheus.NewDesc("voltha_pon_onus_reporting", "Number of ONUs of the PON reporting metrics", ponLabels, nil),
+		ponRxPowerMin:       prometheus.NewDesc("voltha_pon_onu_rx_power_min", "Lowest ONU received optical power of the PON", ponLabels, nil),
+		ponRxPowerAvg:       prometheus.NewDesc("voltha_pon_onu_rx_power_avg", "Average ONU received optical power of the PON", ponLabels, nil),
+		ponRxPowerMax:       prometheus.NewDesc("voltha_pon_onu_rx_power_max", "Highest ONU received optical power of the PON", ponLabels, nil),
+		oltRxBytes:          prometheus.NewDesc("voltha_olt_onu_rx_bytes_total", "Sum of the bytes received by the ONUs of the OLT", oltLabels, nil),
+		oltTxBytes:          prometheus.NewDesc("voltha_olt_onu_tx_bytes_total", "Sum of the bytes transmitted by the ONUs of the OLT", oltLabels, nil),
+		oltFecUncorrectable: prometheus.NewDesc("voltha_olt_onu_fec_uncorrectable_words", "Sum of the FEC uncorrectable words of the ONUs of the OLT", oltLabels, nil),
+		oltOnusReporting:    prometheus.NewDesc("voltha_olt_onus_reporting", "Number of ONUs of the OLT reporting metrics", oltLabels, nil),
+	}
+}
+
+// onu returns the sample of the ONU the metrics are about, the lock must be held
+func (a *volthaAggregator) onu(data *voltha.MetricInformation) *volthaOnuSample {
+	sample, ok := a.onus[data.GetMetadata().GetDeviceId()]
+	if !ok {
+		sample = &volthaOnuSample{octets: make(map[volthaBridgePortKey]float64)}
+		a.onus[data.GetMetadata().GetDeviceId()] = sample
+	}
+	sample.oltID = data.GetMetadata().GetLogicalDeviceId()
+	if ponID, ok := data.GetMetadata().GetContext()["intf_id"]; ok {
+		sample.ponID = ponID
+	}
+	sample.lastSeen = time.Now()
+	return sample
+}
+
+func (a *volthaAggregator) observeBridgePortStats(data *voltha.MetricInformation) {
+	if a == nil {
+		return
+	}
+	a.Lock()
+	defer a.Unlock()
+
+	context := data.GetMetadata().GetContext()
+	key := volthaBridgePortKey{portNo: context["portno"], upstream: context["upstream"] == "True"}
+	a.onu(data).octets[key] += float64(data.GetMetrics()["octets"])
+}
+
+func (a *volthaAggregator) observeFecStats(data *voltha.MetricInformation) {
+	if a == nil {
+		return
+	}
+	a.Lock()
+	defer a.Unlock()
+
+	a.onu(data).fecUncorrectable = float64(data.GetMetrics()["uncorrectable_code_words"])
+}
+
+func (a *volthaAggregator) observeOpticalStats(data *voltha.MetricInformation) {
+	if a == nil {
+		return
+	}
+	a.Lock()
+	defer a.Unlock()
+
+	if value, ok := data.GetMetrics()["receive_power"]; ok {
+		sample := a.onu(data)
+		sample.rxPower = float64(value)
+		sample.hasRxPower = true
+	}
+}
+
+// Describe implements prometheus.Collector
+func (a *volthaAggregator) Describe(ch chan<- *prometheus.Desc) {
+	if a.perPon {
+		ch <- a.ponRxBytes
+		ch <- a.ponTxBytes
+		ch <- a.ponFecUncorrectable
+		ch <- a.ponOnusReporting
+		ch <- a.ponRxPowerMin
+		ch <- a.ponRxPowerAvg
+		ch <- a.ponRxPowerMax
+	}
+	if a.perOlt {
+		ch <- a.oltRxBytes
+		ch <- a.oltTxBytes
+		ch <- a.oltFecUncorrectable
+		ch <- a.oltOnusReporting
+	}
+}
+
+type volthaAggregate struct {
+	rxBytes          float64
+	txBytes          float64
+	fecUncorrectable float64
+	onus             float64
+	rxPowerMin       float64
+	rxPowerMax       float64
+	rxPowerSum       float64
+	rxPowerCount     float64
+}
+
+func (agg *volthaAggregate) add(sample *volthaOnuSample) {
+	for key, octets := range sample.octets {
+		if key.upstream {
+			agg.txBytes += octets
+		} else {
+			agg.rxBytes += octets
+		}
+	}
+	agg.fecUncorrectable += sample.fecUncorrectable
+	agg.onus++
+	if sample.hasRxPower {
+		if agg.rxPowerCount == 0 {
+			agg.rxPowerMin = sample.rxPower
+			agg.rxPowerMax = sample.rxPower
+		}
+		agg.rxPowerMin = math.Min(agg.rxPowerMin, sample.rxPower)
+		agg.rxPowerMax = math.Max(agg.rxPowerMax, sample.rxPower)
+		agg.rxPowerSum += sample.rxPower
+		agg.rxPowerCount++
+	}
+}
+
+type volthaPonKey struct {
+	oltID string
+	ponID string
+}
+
+// Collect implements prometheus.Collector
+func (a *volthaAggregator) Collect(ch chan<- prometheus.Metric) {
+	pons := make(map[volthaPonKey]*volthaAggregate)
+	olts := make(map[string]*volthaAggregate)
+
+	a.Lock()
+	staleBefore := time.Now().Add(-a.staleAfter)
+	for id, sample := range a.onus {
+		if sample.lastSeen.Before(staleBefore) {
+			delete(a.onus, id)
+			continue
+		}
+		if _, ok := olts[sample.oltID]; !ok {
+			olts[sample.oltID] = &volthaAggregate{}
+		}
+		olts[sample.oltID].add(sample)
+
+		// ONUs with an unknown PON are only part of the OLT aggregates
+		if sample.ponID == "" {
+			continue
+		}
+		key := volthaPonKey{oltID: sample.oltID, ponID: sample.ponID}
+		if _, ok := pons[key]; !ok {
+			pons[key] = &volthaAggregate{}
+		}
+		pons[key].add(sample)
+	}
+	a.Unlock()
+
+	if a.perPon {
+		for key, agg := range pons {
+			ch <- prometheus.MustNewConstMetric(a.ponRxBytes, prometheus.CounterValue, agg.rxBytes, key.oltID, key.ponID)
+			ch <- prometheus.MustNewConstMetric(a.ponTxBytes, prometheus.CounterValue, agg.txBytes, key.oltID, key.ponID)
+			ch <- prometheus.MustNewConstMetric(a.ponFecUncorrectable, prometheus.GaugeValue, agg.fecUncorrectable, key.oltID, key.ponID)
+			ch <- prometheus.MustNewConstMetric(a.ponOnusReporting, prometheus.GaugeValue, agg.onus, key.oltID, key.ponID)
+			if agg.rxPowerCount > 0 {
+				ch <- prometheus.MustNewConstMetric(a.ponRxPowerMin, prometheus.GaugeValue, agg.rxPowerMin, key.oltID, key.ponID)
+				ch <- prometheus.MustNewConstMetric(a.ponRxPowerAvg, prometheus.GaugeValue, agg.rxPowerSum/agg.rxPowerCount, key.oltID, key.ponID)
+				ch <- prometheus.MustNewConstMetric(a.ponRxPowerMax, prometheus.GaugeValue, agg.rxPowerMax, key.oltID, key.ponID)
+			}
+		}
+	}
+	if a.perOlt {
+		for oltID, agg := range olts {
+			ch <- prometheus.MustNewConstMetric(a.oltRxBytes, prometheus.CounterValue, agg.rxBytes, oltID)
+			ch <- prometheus.MustNewConstMetric(a.oltTxBytes, prometheus.CounterValue, agg.txBytes, oltID)
+			ch <- prometheus.MustNewConstMetric(a.oltFecUncorrectable, prometheus.GaugeValue, agg.fecUncorrectable, oltID)
+			ch <- prometheus.MustNewConstMetric(a.oltOnusReporting, prometheus.GaugeValue, agg.onus, oltID)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/opencord/voltha-protos/v5/go/voltha"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func bridgePortStats(deviceID string, portNo string, upstream string, octets float32) *voltha.MetricInformation {
+	return &voltha.MetricInformation{
+		Metadata: &voltha.MetricMetaData{
+			Title:           "Ethernet_Bridge_Port_History",
+			LogicalDeviceId: "of:0000000000000001",
+			DeviceId:        deviceID,
+			Context:         map[string]string{"intf_id": "0", "portno": portNo, "upstream": upstream},
+		},
+		Metrics: map[string]float32{"octets": octets},
+	}
+}
+
+func TestVolthaAggregatorBridgePortOctets(t *testing.T) {
+	logger.Setup("", "ERROR")
+	registry := newTestRegistry()
+	defer func(aggregates *volthaAggregator) { volthaAggregates = aggregates }(volthaAggregates)
+	volthaAggregates = newVolthaAggregator(AggregationInfo{PerOlt: true, PerPon: true})
+	assert.NoError(t, registry.Register(volthaAggregates))
+
+	// the counts of each interval add up, in the aggregates as in the
+	// per-ONU families
+	for _, stats := range []*voltha.MetricInformation{
+		bridgePortStats("onu-1", "16", "True", 100),
+		bridgePortStats("onu-1", "16", "True", 150),
+		bridgePortStats("onu-1", "16", "False", 400),
+		bridgePortStats("onu-1", "17", "False", 50),
+		bridgePortStats("onu-2", "16", "True", 10),
+	} {
+		exportVolthaOnuEthernetBridgePortStats(stats)
+	}
+
+	mfs, err := registry.Gather()
+	assert.NoError(t, err)
+	values := make(map[string]float64)
+	types := make(map[string]dto.MetricType)
+	for _, mf := range mfs {
+		types[mf.GetName()] = mf.GetType()
+		for _, m := range mf.GetMetric() {
+			values[mf.GetName()] += m.GetGauge().GetValue() + m.GetCounter().GetValue()
+		}
+	}
+	assert.Equal(t, 260.0, values["voltha_onu_bridge_port_tx_bytes_total"])
+	assert.Equal(t, 450.0, values["voltha_onu_bridge_port_rx_bytes_total"])
+	for _, aggregate := range []string{"voltha_olt_onu", "voltha_pon_onu"} {
+		assert.Equal(t, values["voltha_onu_bridge_port_tx_bytes_total"], values[aggregate+"_tx_bytes_total"], aggregate)
+		assert.Equal(t, values["voltha_onu_bridge_port_rx_bytes_total"], values[aggregate+"_rx_bytes_total"], aggregate)
+		assert.Equal(t, dto.MetricType_COUNTER, types[aggregate+"_tx_bytes_total"], aggregate)
+		assert.Equal(t, dto.MetricType_COUNTER, types[aggregate+"_rx_bytes_total"], aggregate)
+	}
+	assert.Equal(t, 2.0, values["voltha_olt_onus_reporting"])
+}
@@ -0,0 +1,161 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"sync"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"gerrit.opencord.org/kafka-topic-exporter/utils"
+	"github.com/opencord/voltha-protos/v5/go/voltha"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const volthaPassthroughAll = "*"
+
+// labels every passthrough metric has, the context entries are added after
+var volthaPassthroughLabels = []string{"logical_device_id", "serial_number", "device_id"}
+
+// volthaPassthroughTitle is an allowed KpiEvent2 title and the context
+// entries its metrics are labelled with
+type volthaPassthroughTitle struct {
+	// context entries used as labels, in label order
+	contextKeys []string
+	labelNames  []string
+}
+
+// KpiEvent2 titles allowed to be exported through the passthrough
+var volthaPassthrough = map[string]*volthaPassthroughTitle{}
+
+// volthaPassthroughGauges holds the GaugeVecs created on the fly, by metric
+// name. A nil entry means the metric could not be registered.
+type volthaPassthroughGauges struct {
+	sync.Mutex
+	gauges map[string]*metricVec
+}
+
+var volthaPassthroughMetrics = &volthaPassthroughGauges{
+	gauges: make(map[string]*metricVec),
+}
+
+// setVolthaPassthrough builds the allow-list, the label names of a title
+// are fixed by the configuration whatever the context of the messages is
+func setVolthaPassthrough(titles []PassthroughInfo) {
+	for _, conf := range titles {
+		title := &volthaPassthroughTitle{
+			labelNames: append([]string{}, volthaPassthroughLabels...),
+		}
+		for _, key := range conf.Context {
+			labelName := sanitizeMetricName(key)
+			if labelName == "" || labelName[0] >= '0' && labelName[0] <= '9' || containsString(title.labelNames, labelName) {
+				logger.Warn("Not using context [%s] as label of the [%s] passthrough", key, conf.Title)
+				continue
+			}
+			title.contextKeys = append(title.contextKeys, key)
+			title.labelNames = append(title.labelNames, labelName)
+		}
+		volthaPassthrough[conf.Title] = title
+	}
+}
+
+// volthaPassthroughAllowed returns the allow-list entry of a title, nil if
+// the title is not allowed
+func volthaPassthroughAllowed(title string) *volthaPassthroughTitle {
+	if allowed, ok := volthaPassthrough[title]; ok {
+		return allowed
+	}
+	return volthaPassthrough[volthaPassthroughAll]
+}
+
+// sanitizeMetricName turns a KpiEvent2 title or metric into a valid
+// Prometheus metric or label name component
+func sanitizeMetricName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return '_'
+	}, name)
+}
+
+// get returns the gauge for the metric, creating it when needed
+func (g *volthaPassthroughGauges) get(name string, title string, labelNames []string) *metricVec {
+	g.Lock()
+	defer g.Unlock()
+
+	if vec, ok := g.gauges[name]; ok {
+		return vec
+	}
+
+	vec := newGaugeVec(
+		prometheus.GaugeOpts{
+			Name: name,
+			Help: "VOLTHA " + title + " metric",
+		},
+		labelNames,
+	)
+	vec.group = "voltha_" + sanitizeMetricName(title)
+	if err := metricsRegisterer.Register(vec); err != nil {
+		logger.Error("Cannot register passthrough metric [%s]: %s", name, err.Error())
+		g.gauges[name] = nil
+		return nil
+	}
+	g.gauges[name] = vec
+	return vec
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// exportVolthaPassthroughStats exports the metrics of a KpiEvent2 title
+// without a dedicated handler as voltha_<title>_<metric>
+func exportVolthaPassthroughStats(data *voltha.MetricInformation) {
+	title := data.GetMetadata().GetTitle()
+	allowed := volthaPassthroughAllowed(title)
+	if allowed == nil {
+		logger.Debug("Ignoring KpiEvent2 [%s], not in the passthrough allow-list", title)
+		return
+	}
+
+	context := data.GetMetadata().GetContext()
+	for metric, value := range data.GetMetrics() {
+		name := "voltha_" + sanitizeMetricName(title) + "_" + sanitizeMetricName(metric)
+		gauge := volthaPassthroughMetrics.get(name, title, allowed.labelNames)
+		if gauge == nil {
+			continue
+		}
+
+		// the allowed context entries missing from this sample are left empty
+		labels := []string{
+			data.GetMetadata().GetLogicalDeviceId(),
+			utils.GetOnuSN(data.GetMetadata().GetSerialNo()),
+			data.GetMetadata().GetDeviceId(),
+		}
+		for _, key := range allowed.contextKeys {
+			labels = append(labels, context[key])
+		}
+		gauge.WithLabelValues(labels...).Set(float64(value))
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 Open Networking Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"gerrit.opencord.org/kafka-topic-exporter/common/logger"
+	"github.com/opencord/voltha-protos/v5/go/voltha"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVolthaPassthroughContextLabels(t *testing.T) {
+	logger.Setup("", "ERROR")
+	defer func(allowList map[string]*volthaPassthroughTitle) { volthaPassthrough = allowList }(volthaPassthrough)
+	volthaPassthrough = map[string]*volthaPassthroughTitle{}
+	setVolthaPassthrough([]PassthroughInfo{
+		{Title: "XGPON_TC_History", Context: []string{"portno", "intf_id", "device_id", "1st"}},
+		{Title: volthaPassthroughAll},
+	})
+
+	const name = "voltha_xgpon_tc_history_psbd_hec_errors"
+	defer func() {
+		for _, created := range []string{name, "voltha_vendor_pm_errors"} {
+			volthaPassthroughMetrics.Lock()
+			if vec := volthaPassthroughMetrics.gauges[created]; vec != nil {
+				metricsRegisterer.Unregister(vec)
+			}
+			delete(volthaPassthroughMetrics.gauges, created)
+			volthaPassthroughMetrics.Unlock()
+			metricFamilies.Lock()
+			delete(metricFamilies.vecs, created)
+			metricFamilies.Unlock()
+		}
+	}()
+
+	// the label set comes from the configuration, not from the first message
+	for _, context := range []map[string]string{
+		{"portno": "16", "instance_id": "1"},
+		{"portno": "17", "intf_id": "0", "timestamp": "1636106400"},
+	} {
+		exportVolthaPassthroughStats(&voltha.MetricInformation{
+			Metadata: &voltha.MetricMetaData{
+				Title:           "XGPON_TC_History",
+				LogicalDeviceId: "of:0000000000000001",
+				DeviceId:        "onu-1",
+				Context:         context,
+			},
+			Metrics: map[string]float32{"psbd_hec_errors": 3},
+		})
+	}
+	exportVolthaPassthroughStats(&voltha.MetricInformation{
+		Metadata: &voltha.MetricMetaData{
+			Title:    "Vendor_PM",
+			DeviceId: "onu-1",
+			Context:  map[string]string{"portno": "16"},
+		},
+		Metrics: map[string]float32{"errors": 1},
+	})
+
+	mfs, err := metricsRegistry.Gather()
+	assert.NoError(t, err)
+	series := make(map[string][]map[string]string)
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			labels := make(map[string]string)
+			for _, pair := range m.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+			series[mf.GetName()] = append(series[mf.GetName()], labels)
+		}
+	}
+	assert.ElementsMatch(t, []map[string]string{
+		{"logical_device_id": "of:0000000000000001", "serial_number": "", "device_id": "onu-1", "portno": "16", "intf_id": ""},
+		{"logical_device_id": "of:0000000000000001", "serial_number": "", "device_id": "onu-1", "portno": "17", "intf_id": "0"},
+	}, series[name])
+	// the titles that are not listed get the context entries of "*"
+	assert.Equal(t, []map[string]string{
+		{"logical_device_id": "", "serial_number": "", "device_id": "onu-1"},
+	}, series["voltha_vendor_pm_errors"])
+}